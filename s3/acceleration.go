@@ -0,0 +1,82 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// EnableAcceleration turns on S3 Transfer Acceleration for the bucket. Set
+// Accelerate on the bucket or session to send requests through it. Bucket
+// names containing dots cannot be accelerated.
+func (b *Bucket) EnableAcceleration() (*s3.PutBucketAccelerateConfigurationOutput, error) {
+	return b.EnableAccelerationContext(context.Background())
+}
+
+func (b *Bucket) EnableAccelerationContext(ctx context.Context) (*s3.PutBucketAccelerateConfigurationOutput, error) {
+	return b.putAcceleration(ctx, types.BucketAccelerateStatusEnabled)
+}
+
+// DisableAcceleration suspends S3 Transfer Acceleration for the bucket.
+func (b *Bucket) DisableAcceleration() (*s3.PutBucketAccelerateConfigurationOutput, error) {
+	return b.DisableAccelerationContext(context.Background())
+}
+
+func (b *Bucket) DisableAccelerationContext(ctx context.Context) (*s3.PutBucketAccelerateConfigurationOutput, error) {
+	return b.putAcceleration(ctx, types.BucketAccelerateStatusSuspended)
+}
+
+func (b *Bucket) putAcceleration(ctx context.Context, status types.BucketAccelerateStatus) (*s3.PutBucketAccelerateConfigurationOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.PutBucketAccelerateConfiguration(ctx, &s3.PutBucketAccelerateConfigurationInput{
+		Bucket: b.Name,
+		AccelerateConfiguration: &types.AccelerateConfiguration{
+			Status: status,
+		},
+	}, withoutAccelerate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put bucket accelerate configuration: %w", apiError(err))
+	}
+
+	return out, nil
+}
+
+// AccelerationStatus returns Enabled, Suspended, or an empty status for a
+// bucket that never had acceleration turned on.
+func (b *Bucket) AccelerationStatus() (types.BucketAccelerateStatus, error) {
+	return b.AccelerationStatusContext(context.Background())
+}
+
+func (b *Bucket) AccelerationStatusContext(ctx context.Context) (types.BucketAccelerateStatus, error) {
+	if b.Name == nil || *b.Name == "" {
+		return "", emptyParam("Name")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	out, err := b.Client.GetBucketAccelerateConfiguration(ctx, &s3.GetBucketAccelerateConfigurationInput{
+		Bucket: b.Name,
+	}, withoutAccelerate)
+	if err != nil {
+		return "", fmt.Errorf("failed to get bucket accelerate configuration: %w", apiError(err))
+	}
+
+	return out.Status, nil
+}
@@ -0,0 +1,239 @@
+package s3_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/itispx/goaws/s3"
+)
+
+func TestBucket_Acceleration(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	status, err := bct.AccelerationStatus()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if status != "" {
+		t.Errorf("expected no status, got '%s'", status)
+	}
+
+	_, err = bct.EnableAcceleration()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	status, err = bct.AccelerationStatus()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if status != types.BucketAccelerateStatusEnabled {
+		t.Errorf("expected '%s', got '%s'", types.BucketAccelerateStatusEnabled, status)
+	}
+
+	_, err = bct.DisableAcceleration()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	t.Cleanup(func() {
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+// recordingClient answers every request with status and body, an empty 200
+// by default, and remembers the URL of the last one.
+type recordingClient struct {
+	status int
+	body   string
+	url    string
+}
+
+func (c *recordingClient) Do(req *http.Request) (*http.Response, error) {
+	c.url = req.URL.Scheme + "://" + req.URL.Host + req.URL.Path
+
+	status := c.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(c.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestBucket_UploadObjectEndpointURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		accelerate bool
+		dualStack  aws.DualStackEndpointState
+		host       string
+	}{
+		{
+			name: "Regional",
+			host: "bucket-name.s3.us-west-2.amazonaws.com",
+		},
+		{
+			name:       "Accelerate",
+			accelerate: true,
+			host:       "bucket-name.s3-accelerate.amazonaws.com",
+		},
+		{
+			name:      "DualStack",
+			dualStack: aws.DualStackEndpointStateEnabled,
+			host:      "bucket-name.s3.dualstack.us-west-2.amazonaws.com",
+		},
+		{
+			name:       "AccelerateDualStack",
+			accelerate: true,
+			dualStack:  aws.DualStackEndpointStateEnabled,
+			host:       "bucket-name.s3-accelerate.dualstack.amazonaws.com",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			name := "bucket-name"
+			region := "us-west-2"
+			key := "key-name"
+			file := []byte("goaws")
+			httpClient := &recordingClient{}
+
+			bct := s3.Bucket{
+				Name:   &name,
+				Region: &region,
+				Client: awss3.New(awss3.Options{
+					Region:        region,
+					UseAccelerate: tt.accelerate,
+					EndpointOptions: awss3.EndpointResolverOptions{
+						UseDualStackEndpoint: tt.dualStack,
+					},
+					HTTPClient: httpClient,
+					Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+						return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+					}),
+				}),
+			}
+
+			_, url, err := bct.UploadObject(&s3.BucketUploadObjectInput{
+				Key:  &key,
+				File: &file,
+			})
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			expected := "https://" + tt.host + "/" + key
+
+			if httpClient.url != expected {
+				t.Errorf("expected request to '%s', got '%s'", expected, httpClient.url)
+			}
+			if url != expected {
+				t.Errorf("expected '%s', got '%s'", expected, url)
+			}
+		})
+	}
+}
+
+func TestBucket_AccelerateBucketOperations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		call func(b *s3.Bucket) error
+		host string
+	}{
+		{"UploadObject", func(b *s3.Bucket) error {
+			_, _, err := b.UploadObject(&s3.BucketUploadObjectInput{
+				Key:  aws.String("key-name"),
+				File: &[]byte{},
+			})
+			return err
+		}, "bucket-name.s3-accelerate.amazonaws.com"},
+		{"DeleteCORS", func(b *s3.Bucket) error {
+			_, err := b.DeleteCORS()
+			return err
+		}, "bucket-name.s3.us-west-2.amazonaws.com"},
+		{"DeletePolicy", func(b *s3.Bucket) error {
+			_, err := b.DeletePolicy()
+			return err
+		}, "bucket-name.s3.us-west-2.amazonaws.com"},
+		{"EnableVersioning", func(b *s3.Bucket) error {
+			_, err := b.EnableVersioning()
+			return err
+		}, "bucket-name.s3.us-west-2.amazonaws.com"},
+		{"DisableAccessLogging", func(b *s3.Bucket) error {
+			_, err := b.DisableAccessLogging()
+			return err
+		}, "bucket-name.s3.us-west-2.amazonaws.com"},
+		{"ListObjects", func(b *s3.Bucket) error {
+			_, err := b.ListObjects(&s3.ListObjectsInput{})
+			return err
+		}, "bucket-name.s3.us-west-2.amazonaws.com"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			name := "bucket-name"
+			region := "us-west-2"
+			httpClient := &recordingClient{body: "<ListBucketResult></ListBucketResult>"}
+
+			bct := s3.Bucket{
+				Name:   &name,
+				Region: &region,
+				Client: awss3.New(awss3.Options{
+					Region:        region,
+					UseAccelerate: true,
+					HTTPClient:    httpClient,
+					Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+						return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+					}),
+				}),
+			}
+
+			if err := tt.call(&bct); err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if !strings.HasPrefix(httpClient.url, "https://"+tt.host+"/") {
+				t.Errorf("expected request to '%s', got '%s'", tt.host, httpClient.url)
+			}
+		})
+	}
+}
@@ -0,0 +1,590 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// Predefined groups that can be granted access by URI.
+const (
+	AllUsersGroup           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	AuthenticatedUsersGroup = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+	LogDeliveryGroup        = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+)
+
+// Grantee identifies who a Grant applies to. Exactly one of ID,
+// EmailAddress or URI must be set. DisplayName is only filled in on reads.
+type Grantee struct {
+	ID           string
+	EmailAddress string
+	URI          string
+	DisplayName  string
+}
+
+type Grant struct {
+	Grantee    Grantee
+	Permission types.Permission
+}
+
+func GrantCanonicalUser(id string, permission types.Permission) Grant {
+	return Grant{Grantee: Grantee{ID: id}, Permission: permission}
+}
+
+// GrantEmail grants by the email address of an AWS account. S3 only accepts
+// email grantees in a few older regions.
+func GrantEmail(email string, permission types.Permission) Grant {
+	return Grant{Grantee: Grantee{EmailAddress: email}, Permission: permission}
+}
+
+func GrantGroup(uri string, permission types.Permission) Grant {
+	return Grant{Grantee: Grantee{URI: uri}, Permission: permission}
+}
+
+// AccessControlList is an object or bucket ACL: its owner and the grants
+// made to other accounts and groups.
+type AccessControlList struct {
+	OwnerID          string
+	OwnerDisplayName string
+	Grants           []Grant
+}
+
+func (g *Grant) validate() error {
+	set := 0
+	for _, v := range []string{g.Grantee.ID, g.Grantee.EmailAddress, g.Grantee.URI} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return paramError("Grantee", "exactly one of 'ID', 'EmailAddress' or 'URI' must be set")
+	}
+
+	switch g.Permission {
+	case types.PermissionFullControl, types.PermissionRead, types.PermissionWrite, types.PermissionReadAcp, types.PermissionWriteAcp:
+	case "":
+		return emptyParam("Permission")
+	default:
+		return paramError("Permission", "unsupported permission '%s'", g.Permission)
+	}
+
+	return nil
+}
+
+func (g *Grant) toSDK() types.Grant {
+	grantee := &types.Grantee{}
+
+	switch {
+	case g.Grantee.ID != "":
+		grantee.Type = types.TypeCanonicalUser
+		grantee.ID = aws.String(g.Grantee.ID)
+	case g.Grantee.EmailAddress != "":
+		grantee.Type = types.TypeAmazonCustomerByEmail
+		grantee.EmailAddress = aws.String(g.Grantee.EmailAddress)
+	default:
+		grantee.Type = types.TypeGroup
+		grantee.URI = aws.String(g.Grantee.URI)
+	}
+
+	return types.Grant{
+		Grantee:    grantee,
+		Permission: g.Permission,
+	}
+}
+
+func aclFromSDK(owner *types.Owner, grants []types.Grant) *AccessControlList {
+	acl := &AccessControlList{
+		Grants: make([]Grant, 0, len(grants)),
+	}
+
+	if owner != nil {
+		acl.OwnerID = aws.ToString(owner.ID)
+		acl.OwnerDisplayName = aws.ToString(owner.DisplayName)
+	}
+
+	for _, g := range grants {
+		grant := Grant{Permission: g.Permission}
+		if g.Grantee != nil {
+			grant.Grantee = Grantee{
+				ID:           aws.ToString(g.Grantee.ID),
+				EmailAddress: aws.ToString(g.Grantee.EmailAddress),
+				URI:          aws.ToString(g.Grantee.URI),
+				DisplayName:  aws.ToString(g.Grantee.DisplayName),
+			}
+		}
+
+		acl.Grants = append(acl.Grants, grant)
+	}
+
+	return acl
+}
+
+func validateGrants(grants *[]Grant) error {
+	if grants == nil {
+		return nil
+	}
+
+	for i, g := range *grants {
+		if err := g.validate(); err != nil {
+			return fmt.Errorf("invalid grant %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// accessControlPolicy builds the request body for grants. S3 requires the
+// owner, so when ownerID is empty the current one is looked up.
+func accessControlPolicy(grants []Grant, ownerID *string, currentOwner func() (*AccessControlList, error)) (*types.AccessControlPolicy, error) {
+	policy := &types.AccessControlPolicy{
+		Grants: make([]types.Grant, 0, len(grants)),
+	}
+
+	for _, g := range grants {
+		policy.Grants = append(policy.Grants, g.toSDK())
+	}
+
+	if ownerID != nil && *ownerID != "" {
+		policy.Owner = &types.Owner{ID: ownerID}
+		return policy, nil
+	}
+
+	current, err := currentOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	policy.Owner = &types.Owner{ID: aws.String(current.OwnerID)}
+
+	return policy, nil
+}
+
+// BucketPutObjectACLInput replaces an object's ACL with either a canned ACL
+// or an explicit list of grants. OwnerID defaults to the current owner.
+type BucketPutObjectACLInput struct {
+	Key       *string
+	VersionId *string
+	CannedACL types.ObjectCannedACL
+	Grants    *[]Grant
+	OwnerID   *string
+}
+
+// PutObjectACL replaces an object's ACL with a canned ACL or a list of grants.
+func (b *Bucket) PutObjectACL(input *BucketPutObjectACLInput) (*s3.PutObjectAclOutput, error) {
+	return b.PutObjectACLContext(context.Background(), input)
+}
+
+func (b *Bucket) PutObjectACLContext(ctx context.Context, input *BucketPutObjectACLInput) (*s3.PutObjectAclOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if *input == (BucketPutObjectACLInput{}) {
+		return nil, emptyInput()
+	}
+	if input.Key == nil || *input.Key == "" {
+		return nil, emptyParam("Key")
+	}
+	if input.CannedACL == "" && input.Grants == nil {
+		return nil, paramError("CannedACL", "empty 'CannedACL' or 'Grants' param")
+	}
+	if input.CannedACL != "" && input.Grants != nil {
+		return nil, paramError("Grants", "'CannedACL' and 'Grants' cannot be used together")
+	}
+	if err := validateGrants(input.Grants); err != nil {
+		return nil, err
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	params := &s3.PutObjectAclInput{
+		Bucket:    b.Name,
+		Key:       input.Key,
+		VersionId: input.VersionId,
+		ACL:       input.CannedACL,
+	}
+
+	if input.Grants != nil {
+		policy, err := accessControlPolicy(*input.Grants, input.OwnerID, func() (*AccessControlList, error) {
+			return b.GetObjectACLContext(ctx, &BucketGetObjectACLInput{
+				Key:       input.Key,
+				VersionId: input.VersionId,
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		params.AccessControlPolicy = policy
+	}
+
+	out, err := b.Client.PutObjectAcl(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put object ACL: %w", apiError(err))
+	}
+
+	return out, nil
+}
+
+type BucketGetObjectACLInput struct {
+	Key       *string
+	VersionId *string
+}
+
+// GetObjectACL returns the owner and grants of an object or one of its
+// versions.
+func (b *Bucket) GetObjectACL(input *BucketGetObjectACLInput) (*AccessControlList, error) {
+	return b.GetObjectACLContext(context.Background(), input)
+}
+
+func (b *Bucket) GetObjectACLContext(ctx context.Context, input *BucketGetObjectACLInput) (*AccessControlList, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if *input == (BucketGetObjectACLInput{}) {
+		return nil, emptyInput()
+	}
+	if input.Key == nil || *input.Key == "" {
+		return nil, emptyParam("Key")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.GetObjectAcl(ctx, &s3.GetObjectAclInput{
+		Bucket:    b.Name,
+		Key:       input.Key,
+		VersionId: input.VersionId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object ACL: %w", apiError(err))
+	}
+
+	return aclFromSDK(out.Owner, out.Grants), nil
+}
+
+// BucketPutACLInput replaces the bucket ACL with either a canned ACL or an
+// explicit list of grants. OwnerID defaults to the current owner.
+type BucketPutACLInput struct {
+	CannedACL types.BucketCannedACL
+	Grants    *[]Grant
+	OwnerID   *string
+}
+
+// PutACL replaces the bucket ACL.
+func (b *Bucket) PutACL(input *BucketPutACLInput) (*s3.PutBucketAclOutput, error) {
+	return b.PutACLContext(context.Background(), input)
+}
+
+func (b *Bucket) PutACLContext(ctx context.Context, input *BucketPutACLInput) (*s3.PutBucketAclOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if *input == (BucketPutACLInput{}) {
+		return nil, emptyInput()
+	}
+	if input.CannedACL == "" && input.Grants == nil {
+		return nil, paramError("CannedACL", "empty 'CannedACL' or 'Grants' param")
+	}
+	if input.CannedACL != "" && input.Grants != nil {
+		return nil, paramError("Grants", "'CannedACL' and 'Grants' cannot be used together")
+	}
+	if err := validateGrants(input.Grants); err != nil {
+		return nil, err
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	params := &s3.PutBucketAclInput{
+		Bucket: b.Name,
+		ACL:    input.CannedACL,
+	}
+
+	if input.Grants != nil {
+		policy, err := accessControlPolicy(*input.Grants, input.OwnerID, func() (*AccessControlList, error) {
+			return b.GetACLContext(ctx)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		params.AccessControlPolicy = policy
+	}
+
+	out, err := b.Client.PutBucketAcl(ctx, params, withoutAccelerate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put bucket ACL: %w", apiError(err))
+	}
+
+	return out, nil
+}
+
+// GetACL returns the owner and grants of the bucket.
+func (b *Bucket) GetACL() (*AccessControlList, error) {
+	return b.GetACLContext(context.Background())
+}
+
+func (b *Bucket) GetACLContext(ctx context.Context) (*AccessControlList, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.GetBucketAcl(ctx, &s3.GetBucketAclInput{
+		Bucket: b.Name,
+	}, withoutAccelerate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bucket ACL: %w", apiError(err))
+	}
+
+	return aclFromSDK(out.Owner, out.Grants), nil
+}
+
+// SetObjectOwnership sets the bucket's Object Ownership. With
+// BucketOwnerEnforced, ACLs are disabled and requests that set any ACL other
+// than bucket-owner-full-control are rejected.
+func (b *Bucket) SetObjectOwnership(ownership types.ObjectOwnership) (*s3.PutBucketOwnershipControlsOutput, error) {
+	return b.SetObjectOwnershipContext(context.Background(), ownership)
+}
+
+func (b *Bucket) SetObjectOwnershipContext(ctx context.Context, ownership types.ObjectOwnership) (*s3.PutBucketOwnershipControlsOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if ownership == "" {
+		return nil, emptyParam("ownership")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.PutBucketOwnershipControls(ctx, &s3.PutBucketOwnershipControlsInput{
+		Bucket: b.Name,
+		OwnershipControls: &types.OwnershipControls{
+			Rules: []types.OwnershipControlsRule{
+				{ObjectOwnership: ownership},
+			},
+		},
+	}, withoutAccelerate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put bucket ownership controls: %w", apiError(err))
+	}
+
+	return out, nil
+}
+
+// ObjectOwnership returns the bucket's Object Ownership, or "" when it has
+// no ownership controls.
+func (b *Bucket) ObjectOwnership() (types.ObjectOwnership, error) {
+	return b.ObjectOwnershipContext(context.Background())
+}
+
+func (b *Bucket) ObjectOwnershipContext(ctx context.Context) (types.ObjectOwnership, error) {
+	if b.Name == nil || *b.Name == "" {
+		return "", emptyParam("Name")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	out, err := b.Client.GetBucketOwnershipControls(ctx, &s3.GetBucketOwnershipControlsInput{
+		Bucket: b.Name,
+	}, withoutAccelerate)
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "OwnershipControlsNotFoundError" {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("failed to get bucket ownership controls: %w", apiError(err))
+	}
+
+	if out.OwnershipControls == nil || len(out.OwnershipControls.Rules) == 0 {
+		return "", nil
+	}
+
+	return out.OwnershipControls.Rules[0].ObjectOwnership, nil
+}
+
+// PublicAccessBlock is a bucket's Block Public Access configuration.
+type PublicAccessBlock struct {
+	BlockPublicAcls       bool
+	IgnorePublicAcls      bool
+	BlockPublicPolicy     bool
+	RestrictPublicBuckets bool
+}
+
+// BlockAllPublicAccess turns on every Block Public Access setting.
+func BlockAllPublicAccess() *PublicAccessBlock {
+	return &PublicAccessBlock{
+		BlockPublicAcls:       true,
+		IgnorePublicAcls:      true,
+		BlockPublicPolicy:     true,
+		RestrictPublicBuckets: true,
+	}
+}
+
+// PutPublicAccessBlock replaces the bucket's Block Public Access settings.
+func (b *Bucket) PutPublicAccessBlock(config *PublicAccessBlock) (*s3.PutPublicAccessBlockOutput, error) {
+	return b.PutPublicAccessBlockContext(context.Background(), config)
+}
+
+func (b *Bucket) PutPublicAccessBlockContext(ctx context.Context, config *PublicAccessBlock) (*s3.PutPublicAccessBlockOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if config == nil {
+		return nil, nilInput()
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.PutPublicAccessBlock(ctx, &s3.PutPublicAccessBlockInput{
+		Bucket: b.Name,
+		PublicAccessBlockConfiguration: &types.PublicAccessBlockConfiguration{
+			BlockPublicAcls:       aws.Bool(config.BlockPublicAcls),
+			IgnorePublicAcls:      aws.Bool(config.IgnorePublicAcls),
+			BlockPublicPolicy:     aws.Bool(config.BlockPublicPolicy),
+			RestrictPublicBuckets: aws.Bool(config.RestrictPublicBuckets),
+		},
+	}, withoutAccelerate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put public access block: %w", apiError(err))
+	}
+
+	return out, nil
+}
+
+// GetPublicAccessBlock returns the bucket's Block Public Access settings, or
+// nil when none are configured.
+func (b *Bucket) GetPublicAccessBlock() (*PublicAccessBlock, error) {
+	return b.GetPublicAccessBlockContext(context.Background())
+}
+
+func (b *Bucket) GetPublicAccessBlockContext(ctx context.Context) (*PublicAccessBlock, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.GetPublicAccessBlock(ctx, &s3.GetPublicAccessBlockInput{
+		Bucket: b.Name,
+	}, withoutAccelerate)
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchPublicAccessBlockConfiguration" {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to get public access block: %w", apiError(err))
+	}
+
+	c := out.PublicAccessBlockConfiguration
+	if c == nil {
+		return nil, nil
+	}
+
+	return &PublicAccessBlock{
+		BlockPublicAcls:       aws.ToBool(c.BlockPublicAcls),
+		IgnorePublicAcls:      aws.ToBool(c.IgnorePublicAcls),
+		BlockPublicPolicy:     aws.ToBool(c.BlockPublicPolicy),
+		RestrictPublicBuckets: aws.ToBool(c.RestrictPublicBuckets),
+	}, nil
+}
+
+// DeletePublicAccessBlock removes the bucket's Block Public Access settings,
+// leaving only the account-level ones in effect.
+func (b *Bucket) DeletePublicAccessBlock() (*s3.DeletePublicAccessBlockOutput, error) {
+	return b.DeletePublicAccessBlockContext(context.Background())
+}
+
+func (b *Bucket) DeletePublicAccessBlockContext(ctx context.Context) (*s3.DeletePublicAccessBlockOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.DeletePublicAccessBlock(ctx, &s3.DeletePublicAccessBlockInput{
+		Bucket: b.Name,
+	}, withoutAccelerate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete public access block: %w", apiError(err))
+	}
+
+	return out, nil
+}
+
+// LockDown makes the bucket private: every Block Public Access setting is
+// turned on and ACLs are disabled by enforcing bucket owner Object Ownership.
+func (b *Bucket) LockDown() error {
+	return b.LockDownContext(context.Background())
+}
+
+func (b *Bucket) LockDownContext(ctx context.Context) error {
+	_, err := b.PutPublicAccessBlockContext(ctx, BlockAllPublicAccess())
+	if err != nil {
+		return err
+	}
+
+	_, err = b.SetObjectOwnershipContext(ctx, types.ObjectOwnershipBucketOwnerEnforced)
+
+	return err
+}
@@ -0,0 +1,213 @@
+package s3_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/itispx/goaws/s3"
+)
+
+func TestBucket_ObjectACL(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	_, err = bct.SetObjectOwnership(types.ObjectOwnershipBucketOwnerPreferred)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = bct.PutPublicAccessBlock(&s3.PublicAccessBlock{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	key := "public"
+	file := []byte("goaws")
+
+	_, _, err = bct.UploadObject(&s3.BucketUploadObjectInput{
+		Key:  &key,
+		File: &file,
+		ACL:  types.ObjectCannedACLPublicRead,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	acl, err := bct.GetObjectACL(&s3.BucketGetObjectACLInput{
+		Key: &key,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !hasGrant(acl, s3.GrantGroup(s3.AllUsersGroup, types.PermissionRead)) {
+		t.Errorf("expected a public read grant, got %+v", acl.Grants)
+	}
+
+	grants := []s3.Grant{
+		s3.GrantCanonicalUser(acl.OwnerID, types.PermissionFullControl),
+	}
+
+	_, err = bct.PutObjectACL(&s3.BucketPutObjectACLInput{
+		Key:    &key,
+		Grants: &grants,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	acl, err = bct.GetObjectACL(&s3.BucketGetObjectACLInput{
+		Key: &key,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(acl.Grants) != 1 || hasGrant(acl, s3.GrantGroup(s3.AllUsersGroup, types.PermissionRead)) {
+		t.Errorf("expected only the owner grant, got %+v", acl.Grants)
+	}
+
+	err = bct.LockDown()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ownership, err := bct.ObjectOwnership()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if ownership != types.ObjectOwnershipBucketOwnerEnforced {
+		t.Errorf("expected '%s', got '%s'", types.ObjectOwnershipBucketOwnerEnforced, ownership)
+	}
+
+	block, err := bct.GetPublicAccessBlock()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if *block != *s3.BlockAllPublicAccess() {
+		t.Errorf("expected all public access to be blocked, got %+v", block)
+	}
+
+	_, _, err = bct.UploadObject(&s3.BucketUploadObjectInput{
+		Key:  &key,
+		File: &file,
+		ACL:  types.ObjectCannedACLPublicRead,
+	})
+	if err == nil {
+		t.Error("expected a public ACL to be rejected")
+	}
+
+	t.Cleanup(func() {
+		err = deleteObject(bucket, region, key)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func hasGrant(acl *s3.AccessControlList, want s3.Grant) bool {
+	for _, g := range acl.Grants {
+		g.Grantee.DisplayName = ""
+		if g == want {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestBucket_PutObjectACLCannedAndGrants(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := "key-name"
+	grants := []s3.Grant{
+		s3.GrantGroup(s3.AllUsersGroup, types.PermissionRead),
+	}
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.PutObjectACL(&s3.BucketPutObjectACLInput{
+		Key:       &key,
+		CannedACL: types.ObjectCannedACLPrivate,
+		Grants:    &grants,
+	})
+	if err == nil || err.Error() != "'CannedACL' and 'Grants' cannot be used together" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_PutACLInvalidGrant(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	owner := "owner-id"
+	grants := []s3.Grant{
+		s3.GrantGroup(s3.AllUsersGroup, types.PermissionRead),
+		{Grantee: s3.Grantee{ID: "id", URI: s3.AllUsersGroup}, Permission: types.PermissionRead},
+	}
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.PutACL(&s3.BucketPutACLInput{
+		Grants:  &grants,
+		OwnerID: &owner,
+	})
+	if err == nil || err.Error() != "invalid grant 1: exactly one of 'ID', 'EmailAddress' or 'URI' must be set" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_PutACLEmptyInput(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.PutACL(&s3.BucketPutACLInput{})
+	if err == nil || err.Error() != "empty input" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_SetObjectOwnershipEmpty(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.SetObjectOwnership("")
+	if err == nil || err.Error() != "empty 'ownership' param" {
+		t.Error("invalid error message")
+	}
+}
@@ -0,0 +1,507 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	// EncryptionChunkSize is the amount of plaintext sealed in each AES-GCM
+	// chunk of a client-side encrypted object.
+	EncryptionChunkSize = 64 * 1024
+
+	maxEncryptionChunkSize = 16 * 1024 * 1024
+
+	metaWrappedKey = "goaws-cse-key"
+	metaIV         = "goaws-cse-iv"
+	metaAlgorithm  = "goaws-cse-alg"
+	metaChunkSize  = "goaws-cse-chunk"
+
+	cseAlgorithm = "AES256-GCM-CHUNKED"
+)
+
+// ErrNotEncrypted is returned by EncryptedBucket.GetObject for objects that
+// were not written by EncryptedBucket.
+var ErrNotEncrypted = errors.New("object is not client-side encrypted")
+
+// KeyProvider creates and unwraps the per-object data keys used by
+// EncryptedBucket. Wrapped keys are stored with the object, so a provider
+// must be able to unwrap every key it has produced.
+type KeyProvider interface {
+	// GenerateDataKey returns a new 256-bit key and its wrapped form.
+	GenerateDataKey(ctx context.Context) (key, wrapped []byte, err error)
+	DecryptDataKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// StaticKeyProvider wraps data keys with a fixed 256-bit key held in memory.
+// It is meant for tests and local tooling.
+type StaticKeyProvider struct {
+	Key []byte
+}
+
+func (p *StaticKeyProvider) aead() (cipher.AEAD, error) {
+	if len(p.Key) != 32 {
+		return nil, paramError("Key", "'Key' must be 32 bytes")
+	}
+
+	return newGCM(p.Key)
+}
+
+func (p *StaticKeyProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	gcm, err := p.aead()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := make([]byte, 32)
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, err
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+
+	return key, gcm.Seal(nonce, nonce, key, nil), nil
+}
+
+func (p *StaticKeyProvider) DecryptDataKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	gcm, err := p.aead()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(wrapped) < gcm.NonceSize() {
+		return nil, fmt.Errorf("wrapped key is too short")
+	}
+
+	key, err := gcm.Open(nil, wrapped[:gcm.NonceSize()], wrapped[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	return key, nil
+}
+
+// KMSClient is the part of the KMS API used by KMSKeyProvider.
+type KMSClient interface {
+	GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error)
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// KMSKeyProvider has KMS generate data keys under KeyID. The same
+// EncryptionContext must be used to decrypt.
+type KMSKeyProvider struct {
+	Client            KMSClient
+	KeyID             string
+	EncryptionContext map[string]string
+}
+
+func (p *KMSKeyProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	out, err := p.Client.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:             aws.String(p.KeyID),
+		KeySpec:           kmstypes.DataKeySpecAes256,
+		EncryptionContext: p.EncryptionContext,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate data key: %w", apiError(err))
+	}
+
+	return out.Plaintext, out.CiphertextBlob, nil
+}
+
+func (p *KMSKeyProvider) DecryptDataKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	out, err := p.Client.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:             aws.String(p.KeyID),
+		CiphertextBlob:    wrapped,
+		EncryptionContext: p.EncryptionContext,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key: %w", apiError(err))
+	}
+
+	return out.Plaintext, nil
+}
+
+// EncryptedBucket encrypts objects before they leave the process and
+// decrypts them on the way back. Every object gets its own data key, wrapped
+// by Keys and stored in the object's metadata together with the IV.
+//
+// The body is sealed in EncryptionChunkSize chunks, each with its own nonce
+// and authentication tag, so objects of any size are streamed rather than
+// held in memory. Reordered, dropped or truncated chunks fail to decrypt.
+type EncryptedBucket struct {
+	Bucket *Bucket
+	Keys   KeyProvider
+}
+
+type EncryptedUploadInput struct {
+	Body        io.Reader
+	Key         *string
+	Metadata    *map[string]string
+	ContentType *string
+	PartSize    *int64
+	Concurrency *int
+}
+
+// Upload encrypts Body and streams it to the bucket with UploadMultipart.
+func (e *EncryptedBucket) Upload(input *EncryptedUploadInput) (*s3.CompleteMultipartUploadOutput, string, error) {
+	return e.UploadContext(context.Background(), input)
+}
+
+func (e *EncryptedBucket) UploadContext(ctx context.Context, input *EncryptedUploadInput) (*s3.CompleteMultipartUploadOutput, string, error) {
+	if e.Bucket == nil {
+		return nil, "", emptyParam("Bucket")
+	}
+	if e.Keys == nil {
+		return nil, "", emptyParam("Keys")
+	}
+	if input == nil {
+		return nil, "", nilInput()
+	}
+	if *input == (EncryptedUploadInput{}) {
+		return nil, "", emptyInput()
+	}
+	if input.Body == nil {
+		return nil, "", emptyParam("Body")
+	}
+	if input.Key == nil || *input.Key == "" {
+		return nil, "", emptyParam("Key")
+	}
+
+	key, wrapped, err := e.Keys.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return nil, "", fmt.Errorf("failed to generate IV: %w", err)
+	}
+
+	metadata := map[string]string{}
+	if input.Metadata != nil {
+		for k, v := range *input.Metadata {
+			metadata[k] = v
+		}
+	}
+	metadata[metaWrappedKey] = base64.StdEncoding.EncodeToString(wrapped)
+	metadata[metaIV] = base64.StdEncoding.EncodeToString(iv)
+	metadata[metaAlgorithm] = cseAlgorithm
+	metadata[metaChunkSize] = strconv.Itoa(EncryptionChunkSize)
+
+	return e.Bucket.UploadMultipartContext(ctx, &BucketUploadMultipartInput{
+		Body:        newEncryptReader(input.Body, gcm, iv, EncryptionChunkSize),
+		Key:         input.Key,
+		PartSize:    input.PartSize,
+		Concurrency: input.Concurrency,
+		CreateMultipartUploadInput: &s3.CreateMultipartUploadInput{
+			Metadata:    metadata,
+			ContentType: input.ContentType,
+		},
+	})
+}
+
+type EncryptedGetObjectInput struct {
+	Key       *string
+	VersionId *string
+}
+
+// GetObject fetches and decrypts an object written by Upload. The returned
+// Body yields plaintext and reports an error if the ciphertext was altered;
+// ContentLength is the plaintext size.
+func (e *EncryptedBucket) GetObject(input *EncryptedGetObjectInput) (*s3.GetObjectOutput, error) {
+	return e.GetObjectContext(context.Background(), input)
+}
+
+func (e *EncryptedBucket) GetObjectContext(ctx context.Context, input *EncryptedGetObjectInput) (*s3.GetObjectOutput, error) {
+	if e.Bucket == nil {
+		return nil, emptyParam("Bucket")
+	}
+	if e.Keys == nil {
+		return nil, emptyParam("Keys")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if *input == (EncryptedGetObjectInput{}) {
+		return nil, emptyInput()
+	}
+	if input.Key == nil || *input.Key == "" {
+		return nil, emptyParam("Key")
+	}
+
+	out, err := e.Bucket.GetObjectContext(ctx, &BucketGetObjectInput{
+		Key:       input.Key,
+		VersionId: input.VersionId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	body, size, err := e.decrypter(ctx, out)
+	if err != nil {
+		out.Body.Close()
+		return nil, err
+	}
+
+	out.Body = body
+	out.ContentLength = size
+
+	return out, nil
+}
+
+func (e *EncryptedBucket) decrypter(ctx context.Context, out *s3.GetObjectOutput) (io.ReadCloser, *int64, error) {
+	if out.Metadata[metaAlgorithm] != cseAlgorithm {
+		return nil, nil, ErrNotEncrypted
+	}
+
+	wrapped, err := base64.StdEncoding.DecodeString(out.Metadata[metaWrappedKey])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid wrapped key: %w", err)
+	}
+
+	iv, err := base64.StdEncoding.DecodeString(out.Metadata[metaIV])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid IV: %w", err)
+	}
+
+	chunkSize, err := strconv.Atoi(out.Metadata[metaChunkSize])
+	if err != nil || chunkSize < 1 || chunkSize > maxEncryptionChunkSize {
+		return nil, nil, fmt.Errorf("invalid chunk size '%s'", out.Metadata[metaChunkSize])
+	}
+
+	key, err := e.Keys.DecryptDataKey(ctx, wrapped)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(iv) != gcm.NonceSize() {
+		return nil, nil, fmt.Errorf("invalid IV length %d", len(iv))
+	}
+
+	var size *int64
+	if out.ContentLength != nil {
+		sealed := int64(chunkSize + gcm.Overhead())
+		chunks := max((*out.ContentLength+sealed-1)/sealed, 1)
+		size = aws.Int64(*out.ContentLength - chunks*int64(gcm.Overhead()))
+	}
+
+	return &decryptReader{
+		body:      out.Body,
+		gcm:       gcm,
+		iv:        iv,
+		chunkSize: chunkSize,
+	}, size, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce derives a unique nonce per chunk by XORing the chunk index
+// into the last eight bytes of the object IV.
+func chunkNonce(iv []byte, index uint64) []byte {
+	nonce := bytes.Clone(iv)
+
+	var ctr [8]byte
+	binary.BigEndian.PutUint64(ctr[:], index)
+	for i := range ctr {
+		nonce[len(nonce)-8+i] ^= ctr[i]
+	}
+
+	return nonce
+}
+
+// chunkAAD authenticates the chunk position and whether it is the last one,
+// so chunks cannot be reordered and the object cannot be truncated.
+func chunkAAD(index uint64, last bool) []byte {
+	aad := make([]byte, 9)
+	binary.BigEndian.PutUint64(aad, index)
+	if last {
+		aad[8] = 1
+	}
+
+	return aad
+}
+
+type encryptReader struct {
+	src       io.Reader
+	gcm       cipher.AEAD
+	iv        []byte
+	index     uint64
+	cur, next []byte
+	out       []byte
+	err       error
+	done      bool
+}
+
+func newEncryptReader(src io.Reader, gcm cipher.AEAD, iv []byte, chunkSize int) *encryptReader {
+	return &encryptReader{
+		src:  src,
+		gcm:  gcm,
+		iv:   iv,
+		cur:  make([]byte, 0, chunkSize),
+		next: make([]byte, 0, chunkSize),
+	}
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+
+		r.seal()
+	}
+
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+
+	return n, nil
+}
+
+// seal encrypts the next chunk. A chunk is only sealed once the following
+// one has been read, since the last chunk is marked as such.
+func (r *encryptReader) seal() {
+	if r.index == 0 {
+		var err error
+		r.cur, err = readChunk(r.src, r.cur[:cap(r.cur)])
+		if err != nil {
+			r.err = err
+			return
+		}
+	}
+
+	var err error
+	r.next, err = readChunk(r.src, r.next[:cap(r.next)])
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	last := len(r.next) == 0
+	r.out = r.gcm.Seal(r.out[:0], chunkNonce(r.iv, r.index), r.cur, chunkAAD(r.index, last))
+	r.index++
+	r.cur, r.next = r.next, r.cur
+	r.done = last
+}
+
+type decryptReader struct {
+	body      io.ReadCloser
+	gcm       cipher.AEAD
+	iv        []byte
+	chunkSize int
+	index     uint64
+	cur, next []byte
+	started   bool
+	out       []byte
+	err       error
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		r.open()
+	}
+
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+
+	return n, nil
+}
+
+func (r *decryptReader) open() {
+	sealed := r.chunkSize + r.gcm.Overhead()
+
+	if !r.started {
+		r.started = true
+		r.cur = make([]byte, sealed)
+		r.next = make([]byte, sealed)
+
+		var err error
+		r.cur, err = readChunk(r.body, r.cur)
+		if err != nil {
+			r.err = err
+			return
+		}
+	}
+
+	if len(r.cur) == 0 {
+		r.err = io.EOF
+		return
+	}
+
+	var err error
+	r.next, err = readChunk(r.body, r.next[:sealed])
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	last := len(r.next) == 0
+	plain, err := r.gcm.Open(r.cur[:0], chunkNonce(r.iv, r.index), r.cur, chunkAAD(r.index, last))
+	if err != nil {
+		r.err = fmt.Errorf("failed to decrypt chunk %d: %w", r.index, err)
+		return
+	}
+
+	r.out = plain
+	r.index++
+	r.cur, r.next = r.next, r.cur[:cap(r.cur)]
+	if last {
+		r.cur = r.cur[:0]
+		if len(plain) == 0 {
+			r.err = io.EOF
+		}
+	}
+}
+
+func (r *decryptReader) Close() error {
+	return r.body.Close()
+}
+
+// readChunk fills buf from r and returns the filled part. A short result
+// means r is exhausted.
+func readChunk(r io.Reader, buf []byte) ([]byte, error) {
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+
+	return buf[:n], nil
+}
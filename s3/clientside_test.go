@@ -0,0 +1,227 @@
+package s3_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+
+	"github.com/itispx/goaws/s3"
+)
+
+func TestEncryptedBucket_Upload(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.EncryptedBucket{
+		Bucket: &s3.Bucket{
+			Name:   &bucket,
+			Region: &region,
+		},
+		Keys: &s3.StaticKeyProvider{Key: bytes.Repeat([]byte{1}, 32)},
+	}
+
+	key := "encrypted"
+	file := bytes.Repeat([]byte("goaws"), s3.EncryptionChunkSize)
+
+	_, _, err = bct.Upload(&s3.EncryptedUploadInput{
+		Key:  &key,
+		Body: bytes.NewReader(file),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	raw, err := bct.Bucket.GetObject(&s3.BucketGetObjectInput{
+		Key: &key,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	raw.Body.Close()
+
+	if *raw.ContentLength == int64(len(file)) {
+		t.Error("expected the stored object to be ciphertext")
+	}
+
+	got, err := bct.GetObject(&s3.EncryptedGetObjectInput{
+		Key: &key,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	body, err := io.ReadAll(got.Body)
+	got.Body.Close()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !bytes.Equal(body, file) {
+		t.Error("decrypted body does not match")
+	}
+
+	if *got.ContentLength != int64(len(file)) {
+		t.Errorf("expected length %d, got %d", len(file), *got.ContentLength)
+	}
+
+	t.Cleanup(func() {
+		err = deleteObject(bucket, region, key)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func TestStaticKeyProvider(t *testing.T) {
+	t.Parallel()
+
+	p := &s3.StaticKeyProvider{Key: bytes.Repeat([]byte{1}, 32)}
+
+	key, wrapped, err := p.GenerateDataKey(context.Background())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(key) != 32 || bytes.Contains(wrapped, key) {
+		t.Fatal("invalid data key")
+	}
+
+	got, err := p.DecryptDataKey(context.Background(), wrapped)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !bytes.Equal(got, key) {
+		t.Error("unwrapped key does not match")
+	}
+
+	other := &s3.StaticKeyProvider{Key: bytes.Repeat([]byte{2}, 32)}
+
+	_, err = other.DecryptDataKey(context.Background(), wrapped)
+	if err == nil {
+		t.Error("expected unwrapping with another key to fail")
+	}
+}
+
+func TestStaticKeyProvider_InvalidKey(t *testing.T) {
+	t.Parallel()
+
+	p := &s3.StaticKeyProvider{Key: []byte("short")}
+
+	_, _, err := p.GenerateDataKey(context.Background())
+	if err == nil || err.Error() != "'Key' must be 32 bytes" {
+		t.Error("invalid error message")
+	}
+}
+
+type fakeKMS struct {
+	key     []byte
+	context map[string]string
+}
+
+func (f *fakeKMS) GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error) {
+	f.context = params.EncryptionContext
+
+	return &kms.GenerateDataKeyOutput{
+		Plaintext:      f.key,
+		CiphertextBlob: []byte("wrapped"),
+	}, nil
+}
+
+func (f *fakeKMS) Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	return &kms.DecryptOutput{
+		Plaintext: f.key,
+	}, nil
+}
+
+func TestKMSKeyProvider(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeKMS{key: bytes.Repeat([]byte{1}, 32)}
+
+	p := &s3.KMSKeyProvider{
+		Client:            client,
+		KeyID:             "alias/goaws",
+		EncryptionContext: map[string]string{"bucket": "bucket-name"},
+	}
+
+	key, wrapped, err := p.GenerateDataKey(context.Background())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !bytes.Equal(key, client.key) || string(wrapped) != "wrapped" {
+		t.Error("unexpected data key")
+	}
+
+	if client.context["bucket"] != "bucket-name" {
+		t.Error("encryption context was not sent")
+	}
+}
+
+func TestEncryptedBucket_UploadNilInput(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.EncryptedBucket{
+		Bucket: &s3.Bucket{Name: &name},
+		Keys:   &s3.StaticKeyProvider{Key: bytes.Repeat([]byte{1}, 32)},
+	}
+
+	_, _, err := bct.Upload(nil)
+	if err == nil || err.Error() != "nil input" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestEncryptedBucket_UploadEmptyKey(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.EncryptedBucket{
+		Bucket: &s3.Bucket{Name: &name},
+		Keys:   &s3.StaticKeyProvider{Key: bytes.Repeat([]byte{1}, 32)},
+	}
+
+	_, _, err := bct.Upload(&s3.EncryptedUploadInput{
+		Body: bytes.NewReader([]byte("goaws")),
+	})
+	if err == nil || err.Error() != "empty 'Key' param" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestEncryptedBucket_GetObjectEmptyKeys(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := "key-name"
+
+	bct := s3.EncryptedBucket{
+		Bucket: &s3.Bucket{Name: &name},
+	}
+
+	_, err := bct.GetObject(&s3.EncryptedGetObjectInput{
+		Key: &key,
+	})
+	if err == nil || err.Error() != "empty 'Keys' param" {
+		t.Error("invalid error message")
+	}
+}
@@ -0,0 +1,493 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	// MaxCopyObjectSize is the largest source a single CopyObject call can
+	// handle. Bigger objects have to be copied part by part.
+	MaxCopyObjectSize int64 = 5 * 1024 * 1024 * 1024
+
+	DefaultCopyPartSize int64 = 128 * 1024 * 1024
+)
+
+type BucketCopyObjectInput struct {
+	Key             *string
+	SourceVersionId *string
+
+	// Destination defaults to the source bucket and DestinationKey to Key.
+	Destination    *Bucket
+	DestinationKey *string
+
+	// Metadata, ContentType and Tagging replace the source values when set
+	// and are copied from the source otherwise.
+	Metadata    *map[string]string
+	ContentType *string
+	Tagging     *map[string]string
+
+	// StorageClass keeps the source setting when empty.
+	StorageClass types.StorageClass
+
+	// Encryption applies to the destination and defaults to the destination
+	// bucket's setting, then to the source object's SSE-S3 or SSE-KMS
+	// settings. The source's KMS key is only kept within its region; a copy
+	// to another region uses the destination's default key.
+	// SourceEncryption defaults to the source bucket's setting and only
+	// matters when the source uses SSE-C.
+	Encryption       *Encryption
+	SourceEncryption *Encryption
+
+	MultipartThreshold *int64
+	PartSize           *int64
+	Concurrency        *int
+}
+
+type CopyObjectOutput struct {
+	ETag      *string
+	VersionId *string
+	Multipart bool
+}
+
+// CopyObject copies an object within the bucket or into input.Destination,
+// which may live in another region. Sources up to MultipartThreshold
+// (MaxCopyObjectSize by default) use a single CopyObject call; larger ones
+// are copied with parallel UploadPartCopy requests. The source is pinned to
+// the ETag seen when the copy starts.
+func (b *Bucket) CopyObject(input *BucketCopyObjectInput) (*CopyObjectOutput, string, error) {
+	return b.CopyObjectContext(context.Background(), input)
+}
+
+func (b *Bucket) CopyObjectContext(ctx context.Context, input *BucketCopyObjectInput) (*CopyObjectOutput, string, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, "", emptyParam("Name")
+	}
+	if input == nil {
+		return nil, "", nilInput()
+	}
+	if *input == (BucketCopyObjectInput{}) {
+		return nil, "", emptyInput()
+	}
+	if input.Key == nil || *input.Key == "" {
+		return nil, "", emptyParam("Key")
+	}
+
+	dst := input.Destination
+	if dst == nil {
+		dst = b
+	}
+	if dst.Name == nil || *dst.Name == "" {
+		return nil, "", emptyParam("Destination.Name")
+	}
+
+	dstKey := input.Key
+	if input.DestinationKey != nil {
+		if *input.DestinationKey == "" {
+			return nil, "", emptyParam("DestinationKey")
+		}
+
+		dstKey = input.DestinationKey
+	}
+
+	threshold := MaxCopyObjectSize
+	if input.MultipartThreshold != nil {
+		if *input.MultipartThreshold < 1 || *input.MultipartThreshold > MaxCopyObjectSize {
+			return nil, "", paramError("MultipartThreshold", "'MultipartThreshold' must be between 1 and %d bytes", MaxCopyObjectSize)
+		}
+
+		threshold = *input.MultipartThreshold
+	}
+
+	_, concurrency, err := multipartSettings(nil, input.Concurrency)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if input.PartSize != nil && (*input.PartSize < MinPartSize || *input.PartSize > MaxCopyObjectSize) {
+		return nil, "", paramError("PartSize", "'PartSize' must be between %d and %d bytes", MinPartSize, MaxCopyObjectSize)
+	}
+
+	srcEnc, err := b.encryption(input.SourceEncryption)
+	if err != nil {
+		return nil, "", err
+	}
+
+	dstEnc, err := dst.encryption(input.Encryption)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	if dst.Client == nil {
+		_, err := dst.NewSessionContext(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	params := &s3.HeadObjectInput{
+		Bucket:    b.Name,
+		Key:       input.Key,
+		VersionId: input.SourceVersionId,
+	}
+	srcEnc.applyHeadObject(params)
+
+	head, err := b.Client.HeadObject(ctx, params)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to head source object: %w", apiError(err))
+	}
+
+	c := &objectCopy{
+		src:       b,
+		dst:       dst,
+		key:       input.Key,
+		dstKey:    dstKey,
+		input:     input,
+		head:      head,
+		srcEnc:    srcEnc,
+		dstEnc:    dstEnc,
+		source:    copySource(*b.Name, *input.Key, head.VersionId),
+		partSize:  input.PartSize,
+		workers:   concurrency,
+		threshold: threshold,
+	}
+
+	out, err := c.copy(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return out, dst.objectURL(ctx, *dstKey), nil
+}
+
+// region returns the bucket's region, or its client's when Region is not
+// set.
+func (b *Bucket) region() string {
+	if b.Region != nil && *b.Region != "" {
+		return *b.Region
+	}
+
+	return b.Client.Options().Region
+}
+
+// copySource builds the x-amz-copy-source value, escaping each path segment
+// of the key.
+func copySource(bucket, key string, versionID *string) *string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	source := url.PathEscape(bucket) + "/" + strings.Join(segments, "/")
+	if versionID != nil && *versionID != "" {
+		source += "?versionId=" + url.QueryEscape(*versionID)
+	}
+
+	return aws.String(source)
+}
+
+type objectCopy struct {
+	src, dst    *Bucket
+	key, dstKey *string
+	input       *BucketCopyObjectInput
+	head        *s3.HeadObjectOutput
+	srcEnc      *Encryption
+	dstEnc      *Encryption
+	source      *string
+	partSize    *int64
+	workers     int
+	threshold   int64
+}
+
+func (c *objectCopy) copy(ctx context.Context) (*CopyObjectOutput, error) {
+	if aws.ToInt64(c.head.ContentLength) <= c.threshold {
+		return c.copySingle(ctx)
+	}
+
+	return c.copyMultipart(ctx)
+}
+
+func (c *objectCopy) storageClass() types.StorageClass {
+	if c.input.StorageClass != "" {
+		return c.input.StorageClass
+	}
+
+	return c.head.StorageClass
+}
+
+// encryption returns the destination's settings: the caller's, else the
+// source object's SSE-S3 or SSE-KMS settings.
+func (c *objectCopy) encryption() *Encryption {
+	if c.dstEnc != nil {
+		return c.dstEnc
+	}
+
+	switch c.head.ServerSideEncryption {
+	case "":
+		return nil
+	case types.ServerSideEncryptionAwsKms, types.ServerSideEncryptionAwsKmsDsse:
+		enc := &Encryption{
+			Algorithm:        c.head.ServerSideEncryption,
+			BucketKeyEnabled: aws.ToBool(c.head.BucketKeyEnabled),
+		}
+
+		// KMS keys only exist in their own region, so a copy to another
+		// region uses the destination's default key instead.
+		if c.src.region() == c.dst.region() {
+			enc.KMSKeyID = aws.ToString(c.head.SSEKMSKeyId)
+		}
+
+		return enc
+	}
+
+	return &Encryption{Algorithm: c.head.ServerSideEncryption}
+}
+
+func (c *objectCopy) replacesMetadata() bool {
+	return c.input.Metadata != nil || c.input.ContentType != nil
+}
+
+func (c *objectCopy) metadata() map[string]string {
+	if c.input.Metadata != nil {
+		return *c.input.Metadata
+	}
+
+	return c.head.Metadata
+}
+
+func (c *objectCopy) contentType() *string {
+	if c.input.ContentType != nil {
+		return c.input.ContentType
+	}
+
+	return c.head.ContentType
+}
+
+func (c *objectCopy) copySingle(ctx context.Context) (*CopyObjectOutput, error) {
+	in := &s3.CopyObjectInput{
+		Bucket:            c.dst.Name,
+		Key:               c.dstKey,
+		CopySource:        c.source,
+		CopySourceIfMatch: c.head.ETag,
+		StorageClass:      c.storageClass(),
+	}
+	c.encryption().applyCopyObject(in)
+
+	if c.replacesMetadata() {
+		in.MetadataDirective = types.MetadataDirectiveReplace
+		in.Metadata = c.metadata()
+		in.ContentType = c.contentType()
+		in.CacheControl = c.head.CacheControl
+		in.ContentDisposition = c.head.ContentDisposition
+		in.ContentEncoding = c.head.ContentEncoding
+		in.ContentLanguage = c.head.ContentLanguage
+		in.WebsiteRedirectLocation = c.head.WebsiteRedirectLocation
+	}
+
+	if c.input.Tagging != nil {
+		in.TaggingDirective = types.TaggingDirectiveReplace
+		in.Tagging = aws.String(encodeTags(*c.input.Tagging))
+	}
+
+	in.CopySourceSSECustomerAlgorithm, in.CopySourceSSECustomerKey, in.CopySourceSSECustomerKeyMD5 = c.srcEnc.customer()
+
+	out, err := c.dst.Client.CopyObject(ctx, in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy object: %w", apiError(err))
+	}
+
+	res := &CopyObjectOutput{
+		VersionId: out.VersionId,
+	}
+	if out.CopyObjectResult != nil {
+		res.ETag = out.CopyObjectResult.ETag
+	}
+
+	return res, nil
+}
+
+func (c *objectCopy) copyMultipart(ctx context.Context) (*CopyObjectOutput, error) {
+	size := aws.ToInt64(c.head.ContentLength)
+
+	partSize := DefaultCopyPartSize
+	if c.partSize != nil {
+		partSize = *c.partSize
+	}
+	if (size+partSize-1)/partSize > MaxUploadParts {
+		if c.partSize != nil {
+			return nil, fmt.Errorf("source exceeds %d parts of %d bytes", MaxUploadParts, partSize)
+		}
+
+		partSize = (size + MaxUploadParts - 1) / MaxUploadParts
+	}
+
+	var tags string
+	if c.input.Tagging != nil {
+		tags = encodeTags(*c.input.Tagging)
+	} else {
+		out, err := c.src.Client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+			Bucket:    c.src.Name,
+			Key:       c.key,
+			VersionId: c.head.VersionId,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get source tags: %w", apiError(err))
+		}
+
+		t := map[string]string{}
+		for _, tag := range out.TagSet {
+			t[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+		tags = encodeTags(t)
+	}
+
+	create := &s3.CreateMultipartUploadInput{
+		Bucket:                  c.dst.Name,
+		Key:                     c.dstKey,
+		Metadata:                c.metadata(),
+		ContentType:             c.contentType(),
+		CacheControl:            c.head.CacheControl,
+		ContentDisposition:      c.head.ContentDisposition,
+		ContentEncoding:         c.head.ContentEncoding,
+		ContentLanguage:         c.head.ContentLanguage,
+		WebsiteRedirectLocation: c.head.WebsiteRedirectLocation,
+		StorageClass:            c.storageClass(),
+		Tagging:                 nilIfEmpty(tags),
+	}
+	c.encryption().applyCreateMultipartUpload(create)
+
+	created, err := c.dst.Client.CreateMultipartUpload(ctx, create)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create multipart upload: %w", apiError(err))
+	}
+
+	parts, err := c.copyParts(ctx, created.UploadId, size, partSize)
+	if err != nil {
+		return nil, c.dst.abortMultipartUpload(ctx, c.dstKey, created.UploadId, err)
+	}
+
+	complete := &s3.CompleteMultipartUploadInput{
+		Bucket:   c.dst.Name,
+		Key:      c.dstKey,
+		UploadId: created.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: parts,
+		},
+	}
+	c.dstEnc.applyCompleteMultipartUpload(complete)
+
+	out, err := c.dst.Client.CompleteMultipartUpload(ctx, complete)
+	if err != nil {
+		return nil, c.dst.abortMultipartUpload(ctx, c.dstKey, created.UploadId, fmt.Errorf("failed to complete multipart upload: %w", apiError(err)))
+	}
+
+	return &CopyObjectOutput{
+		ETag:      out.ETag,
+		VersionId: out.VersionId,
+		Multipart: true,
+	}, nil
+}
+
+func (c *objectCopy) copyParts(ctx context.Context, uploadID *string, size, partSize int64) ([]types.CompletedPart, error) {
+	w, ctx := newWorkers(ctx)
+
+	var (
+		mu    sync.Mutex
+		parts []types.CompletedPart
+	)
+
+	numbers := make(chan int32)
+
+	w.start(c.workers, func() {
+		for n := range numbers {
+			start := int64(n-1) * partSize
+			end := min(start+partSize, size) - 1
+
+			in := &s3.UploadPartCopyInput{
+				Bucket:            c.dst.Name,
+				Key:               c.dstKey,
+				UploadId:          uploadID,
+				PartNumber:        aws.Int32(n),
+				CopySource:        c.source,
+				CopySourceIfMatch: c.head.ETag,
+				CopySourceRange:   aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+			}
+			c.dstEnc.applyUploadPartCopy(in)
+			in.CopySourceSSECustomerAlgorithm, in.CopySourceSSECustomerKey, in.CopySourceSSECustomerKeyMD5 = c.srcEnc.customer()
+
+			out, err := c.dst.Client.UploadPartCopy(ctx, in)
+			if err != nil {
+				w.fail(fmt.Errorf("failed to copy part %d: %w", n, apiError(err)))
+				continue
+			}
+
+			mu.Lock()
+			parts = append(parts, types.CompletedPart{
+				ETag:       out.CopyPartResult.ETag,
+				PartNumber: aws.Int32(n),
+			})
+			mu.Unlock()
+		}
+	})
+
+	total := int32((size + partSize - 1) / partSize)
+
+	for n := int32(1); n <= total; n++ {
+		select {
+		case numbers <- n:
+			continue
+		case <-ctx.Done():
+		}
+
+		break
+	}
+
+	close(numbers)
+
+	if err := w.wait(); err != nil {
+		return nil, err
+	}
+
+	sortParts(parts)
+
+	return parts, nil
+}
+
+func nilIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
+
+// encodeTags formats tags the way the x-amz-tagging header expects, sorted
+// by key so the result is stable.
+func encodeTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	values := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, url.QueryEscape(k)+"="+url.QueryEscape(tags[k]))
+	}
+
+	return strings.Join(values, "&")
+}
@@ -0,0 +1,338 @@
+package s3_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/itispx/goaws/s3"
+	"github.com/itispx/goaws/s3/s3test"
+)
+
+func TestBucket_CopyObject(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	key := "copy-source"
+	data := bytes.Repeat([]byte("goaws"), 1000)
+
+	svc, err := getSVC(region)
+	if err != nil {
+		t.Fatalf("setup fail: %s", err.Error())
+	}
+
+	_, err = svc.PutObject(context.TODO(), &awss3.PutObjectInput{
+		Bucket:   &bucket,
+		Key:      aws.String(key),
+		Body:     bytes.NewReader(data),
+		Metadata: map[string]string{"origin": "goaws"},
+	})
+	if err != nil {
+		t.Fatalf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	dstKey := "copy-destination"
+	contentType := "text/plain"
+
+	out, _, err := bct.CopyObject(&s3.BucketCopyObjectInput{
+		Key:            &key,
+		DestinationKey: &dstKey,
+		ContentType:    &contentType,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if out.Multipart {
+		t.Error("expected a single CopyObject call")
+	}
+
+	got, err := svc.GetObject(context.TODO(), &awss3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &dstKey,
+	})
+	if err != nil {
+		t.Fatalf("get object fail: %s", err.Error())
+	}
+	defer got.Body.Close()
+
+	body, err := io.ReadAll(got.Body)
+	if err != nil {
+		t.Fatalf("get object fail: %s", err.Error())
+	}
+
+	if !bytes.Equal(body, data) {
+		t.Errorf("expected %d bytes, got %d", len(data), len(body))
+	}
+
+	if aws.ToString(got.ContentType) != contentType {
+		t.Errorf("expected content type '%s', got '%s'", contentType, aws.ToString(got.ContentType))
+	}
+
+	if got.Metadata["origin"] != "goaws" {
+		t.Error("expected metadata to be preserved")
+	}
+
+	t.Cleanup(func() {
+		for _, k := range []string{key, dstKey} {
+			err = deleteObject(bucket, region, k)
+			if err != nil {
+				t.Errorf("cleanup fail: %s", err.Error())
+			}
+		}
+
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func TestBucket_CopyObjectMultipart(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	dstBucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	key := "copy-source"
+	data := bytes.Repeat([]byte("goaws"), int(s3.MinPartSize)/2)
+
+	svc, err := getSVC(region)
+	if err != nil {
+		t.Fatalf("setup fail: %s", err.Error())
+	}
+
+	_, err = svc.PutObject(context.TODO(), &awss3.PutObjectInput{
+		Bucket:  &bucket,
+		Key:     aws.String(key),
+		Body:    bytes.NewReader(data),
+		Tagging: aws.String("team=storage"),
+	})
+	if err != nil {
+		t.Fatalf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	dst := s3.Bucket{
+		Name:   &dstBucket,
+		Region: &region,
+	}
+
+	threshold := int64(1)
+	partSize := s3.MinPartSize
+
+	out, _, err := bct.CopyObject(&s3.BucketCopyObjectInput{
+		Key:                &key,
+		Destination:        &dst,
+		MultipartThreshold: &threshold,
+		PartSize:           &partSize,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !out.Multipart {
+		t.Error("expected a multipart copy")
+	}
+
+	got, err := svc.GetObject(context.TODO(), &awss3.GetObjectInput{
+		Bucket: &dstBucket,
+		Key:    &key,
+	})
+	if err != nil {
+		t.Fatalf("get object fail: %s", err.Error())
+	}
+	defer got.Body.Close()
+
+	body, err := io.ReadAll(got.Body)
+	if err != nil {
+		t.Fatalf("get object fail: %s", err.Error())
+	}
+
+	if !bytes.Equal(body, data) {
+		t.Errorf("expected %d bytes, got %d", len(data), len(body))
+	}
+
+	tags, err := svc.GetObjectTagging(context.TODO(), &awss3.GetObjectTaggingInput{
+		Bucket: &dstBucket,
+		Key:    &key,
+	})
+	if err != nil {
+		t.Fatalf("get tagging fail: %s", err.Error())
+	}
+
+	if len(tags.TagSet) != 1 || aws.ToString(tags.TagSet[0].Value) != "storage" {
+		t.Error("expected tags to be preserved")
+	}
+
+	t.Cleanup(func() {
+		err = deleteObject(bucket, region, key)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+
+		err = deleteObject(dstBucket, region, key)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+
+		for _, b := range []string{bucket, dstBucket} {
+			err = deleteBucket(b, region)
+			if err != nil {
+				t.Errorf("cleanup fail: %s", err.Error())
+			}
+		}
+	})
+}
+
+func TestBucket_CopyObjectKMSRegion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		region   string
+		expected *string
+	}{
+		{"SameRegion", "us-east-1", aws.String("source-key")},
+		{"OtherRegion", "eu-west-1", nil},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			src := &s3test.Mock{}
+			src.On("HeadObject", &awss3.HeadObjectOutput{
+				ContentLength:        aws.Int64(5),
+				ETag:                 aws.String(`"etag"`),
+				ServerSideEncryption: types.ServerSideEncryptionAwsKms,
+				SSEKMSKeyId:          aws.String("source-key"),
+			}, nil)
+
+			dst := &s3test.Mock{}
+			dst.On("CopyObject", &awss3.CopyObjectOutput{}, nil)
+
+			bct := s3.Bucket{
+				Name:   aws.String("source"),
+				Region: aws.String("us-east-1"),
+				Client: src,
+			}
+
+			_, _, err := bct.CopyObject(&s3.BucketCopyObjectInput{
+				Key: aws.String("key"),
+				Destination: &s3.Bucket{
+					Name:   aws.String("destination"),
+					Region: &tt.region,
+					Client: dst,
+				},
+			})
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			in := dst.CallsTo("CopyObject")[0].Input.(*awss3.CopyObjectInput)
+			if in.ServerSideEncryption != types.ServerSideEncryptionAwsKms || aws.ToString(in.SSEKMSKeyId) != aws.ToString(tt.expected) {
+				t.Errorf("unexpected encryption %s %v", in.ServerSideEncryption, aws.ToString(in.SSEKMSKeyId))
+			}
+		})
+	}
+}
+
+func TestBucket_CopyObjectNilInput(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.CopyObject(nil)
+	if err == nil || err.Error() != "nil input" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_CopyObjectEmptyInput(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.CopyObject(&s3.BucketCopyObjectInput{})
+	if err == nil || err.Error() != "empty input" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_CopyObjectEmptyKey(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := ""
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.CopyObject(&s3.BucketCopyObjectInput{
+		Key: &key,
+	})
+	if err == nil || err.Error() != "empty 'Key' param" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_CopyObjectEmptyDestinationName(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := "key-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.CopyObject(&s3.BucketCopyObjectInput{
+		Key:         &key,
+		Destination: &s3.Bucket{},
+	})
+	if err == nil || err.Error() != "empty 'Destination.Name' param" {
+		t.Error("invalid error message")
+	}
+}
@@ -0,0 +1,291 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+const MaxCORSRules = 100
+
+var corsMethods = map[string]bool{
+	"GET":    true,
+	"PUT":    true,
+	"POST":   true,
+	"DELETE": true,
+	"HEAD":   true,
+}
+
+// CORSRule is one rule of a bucket CORS configuration. Origins and headers
+// may contain a single "*" wildcard.
+type CORSRule struct {
+	ID             string
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	ExposeHeaders  []string
+	MaxAgeSeconds  int32
+}
+
+// CORSPreflight describes a browser preflight request: the Origin header,
+// Access-Control-Request-Method and Access-Control-Request-Headers.
+type CORSPreflight struct {
+	Origin  string
+	Method  string
+	Headers []string
+}
+
+func (r *CORSRule) validate() error {
+	if len(r.AllowedOrigins) == 0 {
+		return emptyParam("AllowedOrigins")
+	}
+	if len(r.AllowedMethods) == 0 {
+		return emptyParam("AllowedMethods")
+	}
+	if len(r.ID) > 255 {
+		return paramError("ID", "'ID' must be at most 255 characters")
+	}
+	if r.MaxAgeSeconds < 0 {
+		return paramError("MaxAgeSeconds", "'MaxAgeSeconds' must not be negative")
+	}
+
+	for _, m := range r.AllowedMethods {
+		if !corsMethods[m] {
+			return paramError("AllowedMethods", "unsupported method '%s'", m)
+		}
+	}
+	for _, o := range r.AllowedOrigins {
+		if strings.Count(o, "*") > 1 {
+			return paramError("AllowedOrigins", "origin '%s' has more than one wildcard", o)
+		}
+	}
+	for _, h := range r.AllowedHeaders {
+		if strings.Count(h, "*") > 1 {
+			return paramError("AllowedHeaders", "header '%s' has more than one wildcard", h)
+		}
+	}
+
+	return nil
+}
+
+// Allows reports whether the rule matches the preflight: the origin and
+// method must be allowed and so must every requested header.
+func (r *CORSRule) Allows(preflight CORSPreflight) bool {
+	if !matchAny(r.AllowedOrigins, preflight.Origin, false) {
+		return false
+	}
+
+	method := false
+	for _, m := range r.AllowedMethods {
+		if m == preflight.Method {
+			method = true
+			break
+		}
+	}
+	if !method {
+		return false
+	}
+
+	for _, h := range preflight.Headers {
+		h = strings.TrimSpace(h)
+		if h != "" && !matchAny(r.AllowedHeaders, h, true) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// EvaluateCORS applies rules the way S3 does: the first rule that allows the
+// preflight is used. It returns that rule, or false when the preflight would
+// be rejected.
+func EvaluateCORS(rules []CORSRule, preflight CORSPreflight) (*CORSRule, bool) {
+	for i := range rules {
+		if rules[i].Allows(preflight) {
+			return &rules[i], true
+		}
+	}
+
+	return nil, false
+}
+
+func matchAny(patterns []string, value string, foldCase bool) bool {
+	if foldCase {
+		value = strings.ToLower(value)
+	}
+
+	for _, p := range patterns {
+		if foldCase {
+			p = strings.ToLower(p)
+		}
+
+		before, after, wildcard := strings.Cut(p, "*")
+		if !wildcard {
+			if p == value {
+				return true
+			}
+			continue
+		}
+
+		if len(value) >= len(before)+len(after) && strings.HasPrefix(value, before) && strings.HasSuffix(value, after) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *CORSRule) toSDK() types.CORSRule {
+	rule := types.CORSRule{
+		AllowedOrigins: r.AllowedOrigins,
+		AllowedMethods: r.AllowedMethods,
+		AllowedHeaders: r.AllowedHeaders,
+		ExposeHeaders:  r.ExposeHeaders,
+	}
+
+	if r.ID != "" {
+		rule.ID = aws.String(r.ID)
+	}
+	if r.MaxAgeSeconds > 0 {
+		rule.MaxAgeSeconds = aws.Int32(r.MaxAgeSeconds)
+	}
+
+	return rule
+}
+
+func corsRuleFromSDK(r types.CORSRule) CORSRule {
+	return CORSRule{
+		ID:             aws.ToString(r.ID),
+		AllowedOrigins: r.AllowedOrigins,
+		AllowedMethods: r.AllowedMethods,
+		AllowedHeaders: r.AllowedHeaders,
+		ExposeHeaders:  r.ExposeHeaders,
+		MaxAgeSeconds:  aws.ToInt32(r.MaxAgeSeconds),
+	}
+}
+
+type BucketPutCORSInput struct {
+	Rules *[]CORSRule
+}
+
+// PutCORS replaces the bucket's CORS rules.
+func (b *Bucket) PutCORS(input *BucketPutCORSInput) (*s3.PutBucketCorsOutput, error) {
+	return b.PutCORSContext(context.Background(), input)
+}
+
+func (b *Bucket) PutCORSContext(ctx context.Context, input *BucketPutCORSInput) (*s3.PutBucketCorsOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if *input == (BucketPutCORSInput{}) {
+		return nil, emptyInput()
+	}
+	if input.Rules == nil || len(*input.Rules) == 0 {
+		return nil, emptyParam("Rules")
+	}
+	if len(*input.Rules) > MaxCORSRules {
+		return nil, paramError("Rules", "'Rules' must have at most %d rules", MaxCORSRules)
+	}
+
+	rules := make([]types.CORSRule, 0, len(*input.Rules))
+	for i, r := range *input.Rules {
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("invalid CORS rule %d: %w", i, err)
+		}
+
+		rules = append(rules, r.toSDK())
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.PutBucketCors(ctx, &s3.PutBucketCorsInput{
+		Bucket: b.Name,
+		CORSConfiguration: &types.CORSConfiguration{
+			CORSRules: rules,
+		},
+	}, withoutAccelerate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put bucket CORS: %w", apiError(err))
+	}
+
+	return out, nil
+}
+
+// GetCORS returns the bucket's CORS rules, or none when the bucket has no
+// CORS configuration.
+func (b *Bucket) GetCORS() ([]CORSRule, error) {
+	return b.GetCORSContext(context.Background())
+}
+
+func (b *Bucket) GetCORSContext(ctx context.Context) ([]CORSRule, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.GetBucketCors(ctx, &s3.GetBucketCorsInput{
+		Bucket: b.Name,
+	}, withoutAccelerate)
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchCORSConfiguration" {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to get bucket CORS: %w", apiError(err))
+	}
+
+	rules := make([]CORSRule, 0, len(out.CORSRules))
+	for _, r := range out.CORSRules {
+		rules = append(rules, corsRuleFromSDK(r))
+	}
+
+	return rules, nil
+}
+
+// DeleteCORS removes every CORS rule from the bucket.
+func (b *Bucket) DeleteCORS() (*s3.DeleteBucketCorsOutput, error) {
+	return b.DeleteCORSContext(context.Background())
+}
+
+func (b *Bucket) DeleteCORSContext(ctx context.Context) (*s3.DeleteBucketCorsOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.DeleteBucketCors(ctx, &s3.DeleteBucketCorsInput{
+		Bucket: b.Name,
+	}, withoutAccelerate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete bucket CORS: %w", apiError(err))
+	}
+
+	return out, nil
+}
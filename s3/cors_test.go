@@ -0,0 +1,217 @@
+package s3_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/itispx/goaws/s3"
+)
+
+func TestBucket_CORS(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	rules, err := bct.GetCORS()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(rules) != 0 {
+		t.Errorf("expected no rules, got %d", len(rules))
+	}
+
+	input := []s3.CORSRule{
+		{
+			ID:             "uploads",
+			AllowedOrigins: []string{"https://*.example.com"},
+			AllowedMethods: []string{"PUT"},
+			AllowedHeaders: []string{"Content-Type", "x-amz-*"},
+			ExposeHeaders:  []string{"ETag"},
+			MaxAgeSeconds:  3000,
+		},
+	}
+
+	_, err = bct.PutCORS(&s3.BucketPutCORSInput{
+		Rules: &input,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	rules, err = bct.GetCORS()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !reflect.DeepEqual(rules, input) {
+		t.Errorf("expected %+v, got %+v", input, rules)
+	}
+
+	_, err = bct.DeleteCORS()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	t.Cleanup(func() {
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func TestEvaluateCORS(t *testing.T) {
+	t.Parallel()
+
+	rules := []s3.CORSRule{
+		{
+			ID:             "uploads",
+			AllowedOrigins: []string{"https://*.example.com"},
+			AllowedMethods: []string{"PUT", "POST"},
+			AllowedHeaders: []string{"Content-Type", "x-amz-*"},
+		},
+		{
+			ID:             "public",
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET", "HEAD"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		preflight s3.CORSPreflight
+		rule      string
+	}{
+		{
+			name: "Upload",
+			preflight: s3.CORSPreflight{
+				Origin:  "https://app.example.com",
+				Method:  "PUT",
+				Headers: []string{"content-type", "X-Amz-Meta-Owner"},
+			},
+			rule: "uploads",
+		},
+		{
+			name: "UploadOtherOrigin",
+			preflight: s3.CORSPreflight{
+				Origin: "https://example.org",
+				Method: "PUT",
+			},
+		},
+		{
+			name: "UploadHeaderNotAllowed",
+			preflight: s3.CORSPreflight{
+				Origin:  "https://app.example.com",
+				Method:  "PUT",
+				Headers: []string{"Authorization"},
+			},
+		},
+		{
+			name: "PublicGet",
+			preflight: s3.CORSPreflight{
+				Origin: "https://example.org",
+				Method: "GET",
+			},
+			rule: "public",
+		},
+		{
+			name: "PublicGetWithHeader",
+			preflight: s3.CORSPreflight{
+				Origin:  "https://example.org",
+				Method:  "GET",
+				Headers: []string{"Range"},
+			},
+		},
+		{
+			name: "Delete",
+			preflight: s3.CORSPreflight{
+				Origin: "https://app.example.com",
+				Method: "DELETE",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rule, ok := s3.EvaluateCORS(rules, tt.preflight)
+			if ok != (tt.rule != "") {
+				t.Fatalf("expected allowed %v, got %v", tt.rule != "", ok)
+			}
+
+			if ok && rule.ID != tt.rule {
+				t.Errorf("expected rule '%s', got '%s'", tt.rule, rule.ID)
+			}
+		})
+	}
+}
+
+func TestBucket_PutCORSNilInput(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.PutCORS(nil)
+	if err == nil || err.Error() != "nil input" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_PutCORSEmptyRules(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	rules := []s3.CORSRule{}
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.PutCORS(&s3.BucketPutCORSInput{
+		Rules: &rules,
+	})
+	if err == nil || err.Error() != "empty 'Rules' param" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_PutCORSInvalidMethod(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	rules := []s3.CORSRule{
+		{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"PATCH"},
+		},
+	}
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.PutCORS(&s3.BucketPutCORSInput{
+		Rules: &rules,
+	})
+	if err == nil || err.Error() != "invalid CORS rule 0: unsupported method 'PATCH'" {
+		t.Error("invalid error message")
+	}
+}
@@ -0,0 +1,319 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// MaxDeleteObjects is the most keys S3 deletes in one DeleteObjects request.
+const MaxDeleteObjects = 1000
+
+type BucketDeleteObjectsInput struct {
+	Keys *[]string
+
+	// Objects are deleted along with Keys and can name specific versions.
+	Objects *[]types.ObjectIdentifier
+
+	// Concurrency is the number of batches deleted at once.
+	Concurrency *int
+
+	BypassGovernanceRetention *bool
+}
+
+type DeleteObjectsOutput struct {
+	Deleted []types.DeletedObject
+	Errors  []DeleteObjectError
+}
+
+// DeleteObjectError is a key S3 failed to delete. It matches the sentinel for
+// its code with errors.Is, like *APIError.
+type DeleteObjectError struct {
+	Key       string
+	VersionId string
+	Code      string
+	Message   string
+}
+
+func (e *DeleteObjectError) Error() string {
+	return fmt.Sprintf("failed to delete '%s': %s: %s", e.Key, e.Code, e.Message)
+}
+
+func (e *DeleteObjectError) Is(target error) bool {
+	kind := errorKinds[e.Code]
+	return kind != nil && target == kind
+}
+
+// Err joins the per-key errors, or returns nil when every key was deleted.
+func (o *DeleteObjectsOutput) Err() error {
+	if len(o.Errors) == 0 {
+		return nil
+	}
+
+	errs := make([]error, 0, len(o.Errors))
+	for i := range o.Errors {
+		errs = append(errs, &o.Errors[i])
+	}
+
+	return fmt.Errorf("failed to delete %d objects: %w", len(o.Errors), errors.Join(errs...))
+}
+
+// DeleteObjects deletes the keys in batches of MaxDeleteObjects. Keys S3
+// refuses to delete are listed in the output's Errors and reported by its
+// Err method; the returned error is only set when a request fails.
+func (b *Bucket) DeleteObjects(input *BucketDeleteObjectsInput) (*DeleteObjectsOutput, error) {
+	return b.DeleteObjectsContext(context.Background(), input)
+}
+
+func (b *Bucket) DeleteObjectsContext(ctx context.Context, input *BucketDeleteObjectsInput) (*DeleteObjectsOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if *input == (BucketDeleteObjectsInput{}) {
+		return nil, emptyInput()
+	}
+
+	var objects []types.ObjectIdentifier
+	if input.Keys != nil {
+		for _, k := range *input.Keys {
+			if k == "" {
+				return nil, paramError("Keys", "empty key in 'Keys' param")
+			}
+			objects = append(objects, types.ObjectIdentifier{Key: aws.String(k)})
+		}
+	}
+	if input.Objects != nil {
+		for _, o := range *input.Objects {
+			if aws.ToString(o.Key) == "" {
+				return nil, paramError("Objects", "empty key in 'Objects' param")
+			}
+			objects = append(objects, o)
+		}
+	}
+	if len(objects) == 0 {
+		return nil, emptyParam("Keys")
+	}
+
+	concurrency := DefaultConcurrency
+	if input.Concurrency != nil {
+		if *input.Concurrency < 1 {
+			return nil, paramError("Concurrency", "'Concurrency' must be at least 1")
+		}
+		concurrency = *input.Concurrency
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	d := &batchDeleter{
+		bucket:      b,
+		bypass:      input.BypassGovernanceRetention,
+		concurrency: concurrency,
+	}
+
+	return d.run(ctx, func(batch func([]types.ObjectIdentifier) bool) error {
+		for len(objects) > 0 {
+			n := min(len(objects), MaxDeleteObjects)
+			if !batch(objects[:n]) {
+				return nil
+			}
+			objects = objects[n:]
+		}
+
+		return nil
+	})
+}
+
+// DeletePrefix deletes every object whose key starts with prefix. Earlier
+// versions of the objects are kept; use Empty to remove those too.
+func (b *Bucket) DeletePrefix(prefix string) (*DeleteObjectsOutput, error) {
+	return b.DeletePrefixContext(context.Background(), prefix)
+}
+
+func (b *Bucket) DeletePrefixContext(ctx context.Context, prefix string) (*DeleteObjectsOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if prefix == "" {
+		return nil, emptyParam("prefix")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	d := &batchDeleter{
+		bucket:      b,
+		concurrency: DefaultConcurrency,
+	}
+
+	return d.run(ctx, func(batch func([]types.ObjectIdentifier) bool) error {
+		objects := make([]types.ObjectIdentifier, 0, MaxDeleteObjects)
+
+		it := b.IterateObjectsContext(ctx, &IterateObjectsInput{
+			Prefix: aws.String(prefix),
+		})
+		for it.Next() {
+			objects = append(objects, types.ObjectIdentifier{Key: it.Object().Key})
+
+			if len(objects) == MaxDeleteObjects {
+				if !batch(objects) {
+					return nil
+				}
+				objects = make([]types.ObjectIdentifier, 0, MaxDeleteObjects)
+			}
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+
+		if len(objects) > 0 {
+			batch(objects)
+		}
+
+		return nil
+	})
+}
+
+// Empty deletes every object in the bucket, including all versions and
+// delete markers, so that the bucket itself can be deleted.
+func (b *Bucket) Empty() (*DeleteObjectsOutput, error) {
+	return b.EmptyContext(context.Background())
+}
+
+func (b *Bucket) EmptyContext(ctx context.Context) (*DeleteObjectsOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	d := &batchDeleter{
+		bucket:      b,
+		concurrency: DefaultConcurrency,
+	}
+
+	return d.run(ctx, func(batch func([]types.ObjectIdentifier) bool) error {
+		params := &s3.ListObjectVersionsInput{
+			Bucket: b.Name,
+		}
+
+		for {
+			page, err := b.Client.ListObjectVersions(ctx, params, withoutAccelerate)
+			if err != nil {
+				return fmt.Errorf("failed to list object versions: %w", apiError(err))
+			}
+
+			objects := make([]types.ObjectIdentifier, 0, len(page.Versions)+len(page.DeleteMarkers))
+			for _, v := range page.Versions {
+				objects = append(objects, types.ObjectIdentifier{Key: v.Key, VersionId: v.VersionId})
+			}
+			for _, m := range page.DeleteMarkers {
+				objects = append(objects, types.ObjectIdentifier{Key: m.Key, VersionId: m.VersionId})
+			}
+
+			for len(objects) > 0 {
+				n := min(len(objects), MaxDeleteObjects)
+				if !batch(objects[:n]) {
+					return nil
+				}
+				objects = objects[n:]
+			}
+
+			if !aws.ToBool(page.IsTruncated) {
+				return nil
+			}
+
+			params.KeyMarker = page.NextKeyMarker
+			params.VersionIdMarker = page.NextVersionIdMarker
+		}
+	})
+}
+
+type batchDeleter struct {
+	bucket      *Bucket
+	bypass      *bool
+	concurrency int
+}
+
+// run deletes the batches produce passes to its callback with up to
+// concurrency requests in flight. The callback returns false once the
+// deletion has failed and produce should stop.
+func (d *batchDeleter) run(ctx context.Context, produce func(batch func([]types.ObjectIdentifier) bool) error) (*DeleteObjectsOutput, error) {
+	w, ctx := newWorkers(ctx)
+
+	var (
+		mu  sync.Mutex
+		out = &DeleteObjectsOutput{}
+	)
+
+	batches := make(chan []types.ObjectIdentifier)
+
+	w.start(d.concurrency, func() {
+		for objects := range batches {
+			resp, err := d.bucket.Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+				Bucket: d.bucket.Name,
+				Delete: &types.Delete{
+					Objects: objects,
+				},
+				BypassGovernanceRetention: d.bypass,
+			}, withoutAccelerate)
+			if err != nil {
+				w.fail(fmt.Errorf("failed to delete objects: %w", apiError(err)))
+				continue
+			}
+
+			mu.Lock()
+			out.Deleted = append(out.Deleted, resp.Deleted...)
+			for _, e := range resp.Errors {
+				out.Errors = append(out.Errors, DeleteObjectError{
+					Key:       aws.ToString(e.Key),
+					VersionId: aws.ToString(e.VersionId),
+					Code:      aws.ToString(e.Code),
+					Message:   aws.ToString(e.Message),
+				})
+			}
+			mu.Unlock()
+		}
+	})
+
+	err := produce(func(objects []types.ObjectIdentifier) bool {
+		select {
+		case batches <- objects:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+	if err != nil {
+		w.fail(err)
+	}
+
+	close(batches)
+
+	if err := w.wait(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
@@ -0,0 +1,169 @@
+package s3_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/itispx/goaws/s3"
+)
+
+// deleteClient answers every DeleteObjects request with body and counts them.
+type deleteClient struct {
+	body     string
+	requests atomic.Int32
+}
+
+func (c *deleteClient) Do(req *http.Request) (*http.Response, error) {
+	c.requests.Add(1)
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(c.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestBucket_DeletePrefix(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	for i := 0; i < 3; i++ {
+		err := putObject(bucket, region, fmt.Sprintf("prefix/%d", i))
+		if err != nil {
+			t.Errorf("setup fail: %s", err.Error())
+		}
+	}
+
+	err = putObject(bucket, region, "other")
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	out, err := bct.DeletePrefix("prefix/")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(out.Deleted) != 3 || out.Err() != nil {
+		t.Errorf("expected 3 deleted objects, got %+v", out)
+	}
+
+	list, err := bct.ListObjects(nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(list.Contents) != 1 || *list.Contents[0].Key != "other" {
+		t.Errorf("expected only 'other' to be left, got %d objects", len(list.Contents))
+	}
+
+	t.Cleanup(func() {
+		_, err = bct.Delete(&s3.BucketDeleteInput{
+			Force: aws.Bool(true),
+		})
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func TestBucket_DeleteObjectsBatches(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	region := "us-east-1"
+	httpClient := &deleteClient{
+		body: "<DeleteResult><Error><Key>locked</Key><Code>AccessDenied</Code><Message>Access Denied</Message></Error></DeleteResult>",
+	}
+
+	bct := s3.Bucket{
+		Name:   &name,
+		Region: &region,
+	}
+
+	_, err := bct.NewSession(s3.WithStaticCredentials("AKID", "SECRET", ""), s3.WithHTTPClient(httpClient))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	keys := make([]string, 2500)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+	}
+
+	out, err := bct.DeleteObjects(&s3.BucketDeleteObjectsInput{
+		Keys: &keys,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if n := httpClient.requests.Load(); n != 3 {
+		t.Errorf("expected 3 requests, got %d", n)
+	}
+
+	if len(out.Errors) != 3 || out.Errors[0].Key != "locked" {
+		t.Fatalf("expected an error per batch, got %+v", out.Errors)
+	}
+
+	err = out.Err()
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to delete 3 objects: ") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if !errors.Is(err, s3.ErrAccessDenied) {
+		t.Error("expected the error to match ErrAccessDenied")
+	}
+}
+
+func TestBucket_DeleteObjectsEmptyKey(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	keys := []string{"key-name", ""}
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.DeleteObjects(&s3.BucketDeleteObjectsInput{
+		Keys: &keys,
+	})
+	if err == nil || err.Error() != "empty key in 'Keys' param" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_DeletePrefixEmptyPrefix(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.DeletePrefix("")
+	if err == nil || err.Error() != "empty 'prefix' param" {
+		t.Error("invalid error message")
+	}
+}
@@ -0,0 +1,6 @@
+// Package s3 wraps the AWS SDK's S3 client around a Bucket, validating input
+// before any request is sent.
+//
+// Every operation X has an XContext variant that uses the given context for
+// its requests; X itself uses context.Background().
+package s3
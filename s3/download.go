@@ -0,0 +1,441 @@
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+const DefaultMaxRetries = 3
+
+var ErrChecksumMismatch = errors.New("downloaded data does not match object checksum")
+
+type BucketDownloadInput struct {
+	Key         *string
+	Writer      io.WriterAt
+	PartSize    *int64
+	Concurrency *int
+	MaxRetries  *int
+	Encryption  *Encryption
+
+	// Verify reads the data back from Writer, which must then also be an
+	// io.ReaderAt, and checks it against the object's checksum or ETag.
+	// It doubles the I/O, and a file has to be opened for reading too.
+	Verify *bool
+
+	// Progress is called with the number of bytes written so far and the
+	// object size. Calls are serialized.
+	Progress func(written, total int64)
+}
+
+// Download fetches the object with concurrent ranged GETs and writes each
+// range at its offset in Writer. Every range is pinned to the ETag (and
+// version, if any) returned by the initial HEAD, so a concurrent overwrite
+// fails the download instead of mixing two objects. Failed ranges are retried
+// from the last byte written.
+func (b *Bucket) Download(input *BucketDownloadInput) (*s3.HeadObjectOutput, error) {
+	return b.DownloadContext(context.Background(), input)
+}
+
+func (b *Bucket) DownloadContext(ctx context.Context, input *BucketDownloadInput) (*s3.HeadObjectOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if input.Key == nil || *input.Key == "" {
+		return nil, emptyParam("Key")
+	}
+	if input.Writer == nil {
+		return nil, emptyParam("Writer")
+	}
+
+	reader, canVerify := input.Writer.(io.ReaderAt)
+	if aws.ToBool(input.Verify) && !canVerify {
+		return nil, paramError("Verify", "'Verify' requires a 'Writer' that is also an io.ReaderAt")
+	}
+
+	partSize := DefaultPartSize
+	if input.PartSize != nil {
+		if *input.PartSize < 1 {
+			return nil, paramError("PartSize", "'PartSize' must be at least 1 byte")
+		}
+
+		partSize = *input.PartSize
+	}
+
+	_, concurrency, err := multipartSettings(nil, input.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	retries := DefaultMaxRetries
+	if input.MaxRetries != nil {
+		if *input.MaxRetries < 0 {
+			return nil, paramError("MaxRetries", "'MaxRetries' must not be negative")
+		}
+
+		retries = *input.MaxRetries
+	}
+
+	enc, err := b.encryption(input.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	params := &s3.HeadObjectInput{
+		Bucket:       b.Name,
+		Key:          input.Key,
+		ChecksumMode: types.ChecksumModeEnabled,
+	}
+	enc.applyHeadObject(params)
+
+	head, err := b.Client.HeadObject(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to head object: %w", apiError(err))
+	}
+
+	size := aws.ToInt64(head.ContentLength)
+
+	if t, ok := input.Writer.(interface{ Truncate(int64) error }); ok {
+		if err := t.Truncate(size); err != nil {
+			return nil, fmt.Errorf("failed to truncate writer: %w", err)
+		}
+	}
+
+	d := &downloader{
+		bucket:   b,
+		key:      input.Key,
+		head:     head,
+		w:        input.Writer,
+		enc:      enc,
+		retries:  retries,
+		progress: input.Progress,
+	}
+
+	if err := d.download(ctx, partSize, concurrency); err != nil {
+		return nil, err
+	}
+
+	if aws.ToBool(input.Verify) {
+		if err := b.verifyDownload(ctx, input.Key, head, io.NewSectionReader(reader, 0, size)); err != nil {
+			return nil, err
+		}
+	}
+
+	return head, nil
+}
+
+type downloader struct {
+	bucket   *Bucket
+	key      *string
+	head     *s3.HeadObjectOutput
+	w        io.WriterAt
+	enc      *Encryption
+	retries  int
+	progress func(written, total int64)
+
+	mu      sync.Mutex
+	written int64
+}
+
+func (d *downloader) download(ctx context.Context, partSize int64, concurrency int) error {
+	w, ctx := newWorkers(ctx)
+
+	size := aws.ToInt64(d.head.ContentLength)
+
+	offsets := make(chan int64)
+
+	w.start(concurrency, func() {
+		for start := range offsets {
+			err := d.downloadRange(ctx, start, min(start+partSize, size)-1)
+			if err != nil {
+				w.fail(err)
+			}
+		}
+	})
+
+	for start := int64(0); start < size; start += partSize {
+		select {
+		case offsets <- start:
+			continue
+		case <-ctx.Done():
+		}
+
+		break
+	}
+
+	close(offsets)
+
+	return w.wait()
+}
+
+// downloadRange writes the inclusive byte range [start, end], retrying from
+// the first missing byte when a request or its body fails.
+func (d *downloader) downloadRange(ctx context.Context, start, end int64) error {
+	var err error
+
+	for attempt, offset := 0, start; attempt <= d.retries; attempt++ {
+		var n int64
+		n, err = d.getRange(ctx, offset, end)
+		offset += n
+
+		if err == nil || ctx.Err() != nil {
+			break
+		}
+
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() != "InternalError" && apiErr.ErrorCode() != "SlowDown" {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download range %d-%d: %w", start, end, apiError(err))
+	}
+
+	return nil
+}
+
+func (d *downloader) getRange(ctx context.Context, start, end int64) (int64, error) {
+	params := &s3.GetObjectInput{
+		Bucket:    d.bucket.Name,
+		Key:       d.key,
+		Range:     aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+		IfMatch:   d.head.ETag,
+		VersionId: d.head.VersionId,
+	}
+	d.enc.applyGetObject(params)
+
+	out, err := d.bucket.Client.GetObject(ctx, params)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Body.Close()
+
+	n, err := io.Copy(&progressWriter{
+		w: io.NewOffsetWriter(d.w, start),
+		d: d,
+	}, io.LimitReader(out.Body, end-start+1))
+	if err == nil && n != end-start+1 {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return n, err
+}
+
+type progressWriter struct {
+	w io.Writer
+	d *downloader
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+
+	p.d.mu.Lock()
+	p.d.written += int64(n)
+	if p.d.progress != nil {
+		p.d.progress(p.d.written, aws.ToInt64(p.d.head.ContentLength))
+	}
+	p.d.mu.Unlock()
+
+	return n, err
+}
+
+// verifyDownload compares r against the strongest integrity value S3 gave
+// for the object: a full-object checksum if one was stored, otherwise the
+// ETag when it is an MD5 of the content. Composite checksums and ETags of
+// KMS or customer-key encrypted objects cannot be recomputed and are skipped.
+func (b *Bucket) verifyDownload(ctx context.Context, key *string, head *s3.HeadObjectOutput, r io.Reader) error {
+	checksums := []struct {
+		value *string
+		hash  func() hash.Hash
+	}{
+		{head.ChecksumSHA256, sha256.New},
+		{head.ChecksumSHA1, sha1.New},
+		{head.ChecksumCRC32C, func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) }},
+		{head.ChecksumCRC32, func() hash.Hash { return crc32.NewIEEE() }},
+	}
+
+	for _, c := range checksums {
+		if c.value == nil || strings.Contains(*c.value, "-") {
+			continue
+		}
+
+		h := c.hash()
+		if _, err := io.Copy(h, r); err != nil {
+			return fmt.Errorf("failed to read back download: %w", err)
+		}
+
+		if base64.StdEncoding.EncodeToString(h.Sum(nil)) != *c.value {
+			return ErrChecksumMismatch
+		}
+
+		return nil
+	}
+
+	if head.SSECustomerAlgorithm != nil || head.ServerSideEncryption == types.ServerSideEncryptionAwsKms || head.ServerSideEncryption == types.ServerSideEncryptionAwsKmsDsse {
+		return nil
+	}
+
+	etag := strings.Trim(aws.ToString(head.ETag), `"`)
+
+	hashPart, count, multipart := strings.Cut(etag, "-")
+	if !multipart {
+		h := md5.New()
+		if _, err := io.Copy(h, r); err != nil {
+			return fmt.Errorf("failed to read back download: %w", err)
+		}
+
+		if hex.EncodeToString(h.Sum(nil)) != hashPart {
+			return ErrChecksumMismatch
+		}
+
+		return nil
+	}
+
+	// A multipart ETag is the MD5 of the part MD5s. Part boundaries are not
+	// stored, so assume every part but the last matches the first part's size
+	// and only verify when that reproduces the part count.
+	first, err := b.Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:     b.Name,
+		Key:        key,
+		VersionId:  head.VersionId,
+		IfMatch:    head.ETag,
+		PartNumber: aws.Int32(1),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to head first part: %w", apiError(err))
+	}
+
+	partSize := aws.ToInt64(first.ContentLength)
+	size := aws.ToInt64(head.ContentLength)
+	if partSize <= 0 || fmt.Sprint((size+partSize-1)/partSize) != count {
+		return nil
+	}
+
+	sums := md5.New()
+	for {
+		h := md5.New()
+
+		n, err := io.Copy(h, io.LimitReader(r, partSize))
+		if err != nil {
+			return fmt.Errorf("failed to read back download: %w", err)
+		}
+		if n == 0 {
+			break
+		}
+
+		sums.Write(h.Sum(nil))
+	}
+
+	if hex.EncodeToString(sums.Sum(nil)) != hashPart {
+		return ErrChecksumMismatch
+	}
+
+	return nil
+}
+
+// WriteAtBuffer is an in-memory io.WriterAt for Download. It grows as needed
+// and is safe for concurrent use.
+type WriteAtBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func NewWriteAtBuffer(buf []byte) *WriteAtBuffer {
+	return &WriteAtBuffer{buf: buf}
+}
+
+func (b *WriteAtBuffer) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if end := off + int64(len(p)); end > int64(len(b.buf)) {
+		b.grow(end)
+	}
+
+	return copy(b.buf[off:], p), nil
+}
+
+func (b *WriteAtBuffer) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if off >= int64(len(b.buf)) {
+		return 0, io.EOF
+	}
+
+	n := copy(p, b.buf[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+func (b *WriteAtBuffer) Truncate(size int64) error {
+	if size < 0 {
+		return fmt.Errorf("negative size")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if size > int64(len(b.buf)) {
+		b.grow(size)
+	}
+	b.buf = b.buf[:size]
+
+	return nil
+}
+
+func (b *WriteAtBuffer) grow(size int64) {
+	if size <= int64(cap(b.buf)) {
+		n := len(b.buf)
+		b.buf = b.buf[:size]
+		clear(b.buf[n:])
+		return
+	}
+
+	buf := make([]byte, size, max(size, 2*int64(cap(b.buf))))
+	copy(buf, b.buf)
+	b.buf = buf
+}
+
+func (b *WriteAtBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf
+}
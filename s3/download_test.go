@@ -0,0 +1,253 @@
+package s3_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/itispx/goaws/s3"
+)
+
+func TestBucket_Download(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	key := "download-test"
+	data := bytes.Repeat([]byte("goaws"), 1000)
+
+	svc, err := getSVC(region)
+	if err != nil {
+		t.Fatalf("setup fail: %s", err.Error())
+	}
+
+	_, err = svc.PutObject(context.TODO(), &awss3.PutObjectInput{
+		Bucket: &bucket,
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		t.Fatalf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	buf := s3.NewWriteAtBuffer(nil)
+	partSize := int64(777)
+	concurrency := 3
+
+	var written, total int64
+
+	_, err = bct.Download(&s3.BucketDownloadInput{
+		Key:         &key,
+		Writer:      buf,
+		PartSize:    &partSize,
+		Concurrency: &concurrency,
+		Verify:      aws.Bool(true),
+		Progress: func(w, t int64) {
+			written, total = w, t
+		},
+	})
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("expected %d bytes, got %d", len(data), len(buf.Bytes()))
+	}
+
+	if written != int64(len(data)) || total != int64(len(data)) {
+		t.Errorf("expected progress %d/%d, got %d/%d", len(data), len(data), written, total)
+	}
+
+	// A file opened write-only has a ReadAt method that always fails.
+	name := filepath.Join(t.TempDir(), key)
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = bct.Download(&s3.BucketDownloadInput{
+		Key:    &key,
+		Writer: f,
+	})
+	f.Close()
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	got, err := os.ReadFile(name)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("expected %d bytes in file, got %d, %v", len(data), len(got), err)
+	}
+
+	t.Cleanup(func() {
+		err = deleteObject(bucket, region, key)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func TestBucket_DownloadNilInput(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.Download(nil)
+	if err == nil || err.Error() != "nil input" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_DownloadNilKey(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.Download(&s3.BucketDownloadInput{
+		Writer: s3.NewWriteAtBuffer(nil),
+	})
+	if err == nil || err.Error() != "empty 'Key' param" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_DownloadNilWriter(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := "key-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.Download(&s3.BucketDownloadInput{
+		Key: &key,
+	})
+	if err == nil || err.Error() != "empty 'Writer' param" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_DownloadVerifyWithoutReaderAt(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := "key-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.Download(&s3.BucketDownloadInput{
+		Key:    &key,
+		Writer: writerAtFunc(func(p []byte, off int64) (int, error) { return len(p), nil }),
+		Verify: aws.Bool(true),
+	})
+	if err == nil || err.Error() != "'Verify' requires a 'Writer' that is also an io.ReaderAt" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+type writerAtFunc func(p []byte, off int64) (int, error)
+
+func (f writerAtFunc) WriteAt(p []byte, off int64) (int, error) {
+	return f(p, off)
+}
+
+func TestBucket_DownloadZeroPartSize(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := "key-name"
+	partSize := int64(0)
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.Download(&s3.BucketDownloadInput{
+		Key:      &key,
+		Writer:   s3.NewWriteAtBuffer(nil),
+		PartSize: &partSize,
+	})
+	if err == nil || err.Error() != "'PartSize' must be at least 1 byte" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestWriteAtBuffer(t *testing.T) {
+	t.Parallel()
+
+	buf := s3.NewWriteAtBuffer(nil)
+
+	_, err := buf.WriteAt([]byte("world"), 6)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = buf.WriteAt([]byte("hello "), 0)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if string(buf.Bytes()) != "hello world" {
+		t.Errorf("expected 'hello world', got '%s'", buf.Bytes())
+	}
+
+	p := make([]byte, 5)
+
+	n, err := buf.ReadAt(p, 6)
+	if err != nil || n != 5 || string(p) != "world" {
+		t.Errorf("expected 'world', got '%s' (%v)", p[:n], err)
+	}
+
+	_, err = buf.ReadAt(p, 11)
+	if err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+
+	err = buf.Truncate(5)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = buf.Truncate(7)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !bytes.Equal(buf.Bytes(), []byte("hello\x00\x00")) {
+		t.Errorf("expected truncated buffer to be zero filled, got %q", buf.Bytes())
+	}
+}
@@ -0,0 +1,216 @@
+package s3
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// CustomerKeySize is the size of an SSE-C key: SSE-C always uses AES-256.
+const CustomerKeySize = 32
+
+// Encryption selects server-side encryption for object requests. Use
+// SSES3, SSEKMS or SSEC to build one, and set it on Bucket to apply it to
+// every call or on an operation's input to override the bucket setting.
+type Encryption struct {
+	// Algorithm is AES256 for SSE-S3, or aws:kms / aws:kms:dsse for SSE-KMS.
+	// It is empty for SSE-C.
+	Algorithm        types.ServerSideEncryption
+	KMSKeyID         string
+	BucketKeyEnabled bool
+
+	// CustomerKey is the raw SSE-C key. S3 does not store it, so the same
+	// key has to be sent to read the object back.
+	CustomerKey []byte
+}
+
+func SSES3() *Encryption {
+	return &Encryption{Algorithm: types.ServerSideEncryptionAes256}
+}
+
+// SSEKMS encrypts with a KMS key. An empty keyID uses the AWS managed key
+// for S3.
+func SSEKMS(keyID string, bucketKeyEnabled bool) *Encryption {
+	return &Encryption{
+		Algorithm:        types.ServerSideEncryptionAwsKms,
+		KMSKeyID:         keyID,
+		BucketKeyEnabled: bucketKeyEnabled,
+	}
+}
+
+func SSEC(key []byte) *Encryption {
+	return &Encryption{CustomerKey: key}
+}
+
+func (e *Encryption) validate() error {
+	if e.CustomerKey != nil {
+		if len(e.CustomerKey) != CustomerKeySize {
+			return paramError("CustomerKey", "'CustomerKey' must be %d bytes", CustomerKeySize)
+		}
+		if e.Algorithm != "" || e.KMSKeyID != "" || e.BucketKeyEnabled {
+			return paramError("CustomerKey", "'CustomerKey' cannot be combined with other encryption settings")
+		}
+
+		return nil
+	}
+
+	switch e.Algorithm {
+	case types.ServerSideEncryptionAes256:
+		if e.KMSKeyID != "" || e.BucketKeyEnabled {
+			return paramError("KMSKeyID", "'KMSKeyID' and 'BucketKeyEnabled' require SSE-KMS")
+		}
+	case types.ServerSideEncryptionAwsKms, types.ServerSideEncryptionAwsKmsDsse:
+	case "":
+		return emptyParam("Algorithm")
+	default:
+		return paramError("Algorithm", "unsupported 'Algorithm' '%s'", e.Algorithm)
+	}
+
+	return nil
+}
+
+// encryption returns the settings for a call: the per-call override if any,
+// else the bucket default. The result may be nil.
+func (b *Bucket) encryption(override *Encryption) (*Encryption, error) {
+	e := override
+	if e == nil {
+		e = b.Encryption
+	}
+	if e == nil {
+		return nil, nil
+	}
+
+	if err := e.validate(); err != nil {
+		return nil, fmt.Errorf("invalid encryption: %w", err)
+	}
+
+	return e, nil
+}
+
+// serverSide returns the SSE-S3 / SSE-KMS request fields. They are empty for
+// SSE-C and when e is nil.
+func (e *Encryption) serverSide() (types.ServerSideEncryption, *string, *bool) {
+	if e == nil || e.CustomerKey != nil {
+		return "", nil, nil
+	}
+
+	var keyID *string
+	if e.KMSKeyID != "" {
+		keyID = aws.String(e.KMSKeyID)
+	}
+
+	var bucketKey *bool
+	if e.BucketKeyEnabled {
+		bucketKey = aws.Bool(true)
+	}
+
+	return e.Algorithm, keyID, bucketKey
+}
+
+// opaqueETag reports whether objects written with e get an ETag that is
+// not the MD5 of their content.
+func (e *Encryption) opaqueETag() bool {
+	if e == nil {
+		return false
+	}
+
+	kms := e.Algorithm == types.ServerSideEncryptionAwsKms || e.Algorithm == types.ServerSideEncryptionAwsKmsDsse
+
+	return kms || e.CustomerKey != nil
+}
+
+// customer returns the SSE-C algorithm, base64 key and base64 key MD5, or
+// nils when e is not SSE-C.
+func (e *Encryption) customer() (*string, *string, *string) {
+	if e == nil || e.CustomerKey == nil {
+		return nil, nil, nil
+	}
+
+	sum := md5.Sum(e.CustomerKey)
+
+	return aws.String("AES256"),
+		aws.String(base64.StdEncoding.EncodeToString(e.CustomerKey)),
+		aws.String(base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+func (e *Encryption) applyPutObject(in *s3.PutObjectInput) {
+	if e == nil {
+		return
+	}
+
+	in.ServerSideEncryption, in.SSEKMSKeyId, in.BucketKeyEnabled = e.serverSide()
+	in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = e.customer()
+}
+
+func (e *Encryption) applyCreateMultipartUpload(in *s3.CreateMultipartUploadInput) {
+	if e == nil {
+		return
+	}
+
+	in.ServerSideEncryption, in.SSEKMSKeyId, in.BucketKeyEnabled = e.serverSide()
+	in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = e.customer()
+}
+
+func (e *Encryption) applyCopyObject(in *s3.CopyObjectInput) {
+	if e == nil {
+		return
+	}
+
+	in.ServerSideEncryption, in.SSEKMSKeyId, in.BucketKeyEnabled = e.serverSide()
+	in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = e.customer()
+}
+
+// The remaining request types only carry SSE-C fields: S3 remembers SSE-S3
+// and SSE-KMS settings itself.
+
+func (e *Encryption) applyGetObject(in *s3.GetObjectInput) {
+	if e == nil {
+		return
+	}
+
+	in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = e.customer()
+}
+
+func (e *Encryption) applyHeadObject(in *s3.HeadObjectInput) {
+	if e == nil {
+		return
+	}
+
+	in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = e.customer()
+}
+
+func (e *Encryption) applyUploadPart(in *s3.UploadPartInput) {
+	if e == nil {
+		return
+	}
+
+	in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = e.customer()
+}
+
+func (e *Encryption) applyUploadPartCopy(in *s3.UploadPartCopyInput) {
+	if e == nil {
+		return
+	}
+
+	in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = e.customer()
+}
+
+func (e *Encryption) applyCompleteMultipartUpload(in *s3.CompleteMultipartUploadInput) {
+	if e == nil {
+		return
+	}
+
+	in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = e.customer()
+}
+
+func (e *Encryption) applyListParts(in *s3.ListPartsInput) {
+	if e == nil {
+		return
+	}
+
+	in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = e.customer()
+}
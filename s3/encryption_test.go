@@ -0,0 +1,183 @@
+package s3_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/itispx/goaws/s3"
+)
+
+func TestBucket_EncryptionCustomerKey(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:       &bucket,
+		Region:     &region,
+		Encryption: s3.SSEC(bytes.Repeat([]byte{1}, s3.CustomerKeySize)),
+	}
+
+	key := "encrypted"
+	file := []byte("goaws")
+
+	_, _, err = bct.UploadObject(&s3.BucketUploadObjectInput{
+		Key:  &key,
+		File: &file,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	got, err := bct.GetObject(&s3.BucketGetObjectInput{
+		Key: &key,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	body, err := io.ReadAll(got.Body)
+	got.Body.Close()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !bytes.Equal(body, file) {
+		t.Errorf("expected '%s', got '%s'", file, body)
+	}
+
+	_, err = bct.GetObject(&s3.BucketGetObjectInput{
+		Key:        &key,
+		Encryption: s3.SSEC(bytes.Repeat([]byte{2}, s3.CustomerKeySize)),
+	})
+	if err == nil {
+		t.Error("expected reading with another key to fail")
+	}
+
+	dstKey := "reencrypted"
+
+	_, _, err = bct.CopyObject(&s3.BucketCopyObjectInput{
+		Key:            &key,
+		DestinationKey: &dstKey,
+		Encryption:     s3.SSES3(),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	got, err = bct.GetObject(&s3.BucketGetObjectInput{
+		Key:        &dstKey,
+		Encryption: s3.SSES3(),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	got.Body.Close()
+
+	if got.ServerSideEncryption != types.ServerSideEncryptionAes256 {
+		t.Errorf("expected '%s', got '%s'", types.ServerSideEncryptionAes256, got.ServerSideEncryption)
+	}
+
+	t.Cleanup(func() {
+		for _, k := range []string{key, dstKey} {
+			err = deleteObject(bucket, region, k)
+			if err != nil {
+				t.Errorf("cleanup fail: %s", err.Error())
+			}
+		}
+
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func TestBucket_PresignPutEncryption(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	region := "us-east-1"
+	key := "key-name"
+	duration := time.Minute
+
+	bct := s3.Bucket{
+		Name:   &name,
+		Region: &region,
+		Client: awss3.New(awss3.Options{
+			Region: region,
+			Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+				return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+			}),
+		}),
+	}
+
+	out, err := bct.PresignPut(&s3.PresignPutInput{
+		Key:        &key,
+		Duration:   &duration,
+		Encryption: s3.SSEKMS("alias/goaws", false),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if out.Header["X-Amz-Server-Side-Encryption"] != "aws:kms" || out.Header["X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"] != "alias/goaws" {
+		t.Errorf("expected SSE-KMS signed headers, got %v", out.Header)
+	}
+}
+
+func TestBucket_EncryptionInvalidCustomerKey(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := "key-name"
+	file := []byte("goaws")
+
+	bct := s3.Bucket{
+		Name:       &name,
+		Encryption: s3.SSEC([]byte("short")),
+	}
+
+	_, _, err := bct.UploadObject(&s3.BucketUploadObjectInput{
+		Key:  &key,
+		File: &file,
+	})
+	if err == nil || err.Error() != "invalid encryption: 'CustomerKey' must be 32 bytes" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_EncryptionKeyWithoutKMS(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := "key-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.GetObject(&s3.BucketGetObjectInput{
+		Key: &key,
+		Encryption: &s3.Encryption{
+			Algorithm: types.ServerSideEncryptionAes256,
+			KMSKeyID:  "alias/goaws",
+		},
+	})
+	if err == nil || err.Error() != "invalid encryption: 'KMSKeyID' and 'BucketKeyEnabled' require SSE-KMS" {
+		t.Error("invalid error message")
+	}
+}
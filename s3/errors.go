@@ -0,0 +1,156 @@
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// ErrValidation matches every *ValidationError with errors.Is.
+var ErrValidation = errors.New("invalid input")
+
+// Errors returned by S3 are matched against these with errors.Is. Use
+// errors.As with *APIError for the code and status.
+var (
+	ErrNotFound            = errors.New("resource not found")
+	ErrAccessDenied        = errors.New("access denied")
+	ErrBucketAlreadyExists = errors.New("bucket already exists")
+	ErrBucketNotEmpty      = errors.New("bucket not empty")
+	ErrPreconditionFailed  = errors.New("precondition failed")
+	ErrInvalidObjectState  = errors.New("object is archived")
+	ErrThrottled           = errors.New("request throttled")
+)
+
+// ValidationError is returned when an input is rejected before any request
+// is sent. Field is the name of the offending parameter, empty when the whole
+// input is missing.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+func paramError(field, format string, args ...any) error {
+	return &ValidationError{
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func emptyParam(field string) error {
+	return paramError(field, "empty '%s' param", field)
+}
+
+func nilInput() error {
+	return paramError("", "nil input")
+}
+
+func emptyInput() error {
+	return paramError("", "empty input")
+}
+
+// APIError is an error response from S3. Kind is the sentinel the code maps
+// to, or nil for codes without one. The SDK error stays in the chain, so
+// errors.As with smithy.APIError keeps working.
+type APIError struct {
+	Kind       error
+	Code       string
+	Message    string
+	StatusCode int
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+func (e *APIError) Is(target error) bool {
+	return e.Kind != nil && target == e.Kind
+}
+
+var errorKinds = map[string]error{
+	"NotFound":                             ErrNotFound,
+	"NoSuchKey":                            ErrNotFound,
+	"NoSuchBucket":                         ErrNotFound,
+	"NoSuchVersion":                        ErrNotFound,
+	"NoSuchUpload":                         ErrNotFound,
+	"NoSuchBucketPolicy":                   ErrNotFound,
+	"NoSuchCORSConfiguration":              ErrNotFound,
+	"NoSuchLifecycleConfiguration":         ErrNotFound,
+	"NoSuchPublicAccessBlockConfiguration": ErrNotFound,
+	"NoSuchTagSet":                         ErrNotFound,
+	"OwnershipControlsNotFoundError":       ErrNotFound,
+	"ServerSideEncryptionConfigurationNotFoundError": ErrNotFound,
+
+	"AccessDenied":          ErrAccessDenied,
+	"AccessDeniedException": ErrAccessDenied,
+	"AllAccessDisabled":     ErrAccessDenied,
+	"Forbidden":             ErrAccessDenied,
+	"InvalidAccessKeyId":    ErrAccessDenied,
+	"SignatureDoesNotMatch": ErrAccessDenied,
+	"ExpiredToken":          ErrAccessDenied,
+	"InvalidToken":          ErrAccessDenied,
+
+	"BucketAlreadyExists":     ErrBucketAlreadyExists,
+	"BucketAlreadyOwnedByYou": ErrBucketAlreadyExists,
+
+	"BucketNotEmpty": ErrBucketNotEmpty,
+
+	"PreconditionFailed":         ErrPreconditionFailed,
+	"ConditionalRequestConflict": ErrPreconditionFailed,
+
+	"InvalidObjectState": ErrInvalidObjectState,
+
+	"SlowDown":                 ErrThrottled,
+	"Throttling":               ErrThrottled,
+	"ThrottlingException":      ErrThrottled,
+	"RequestLimitExceeded":     ErrThrottled,
+	"TooManyRequestsException": ErrThrottled,
+}
+
+var statusKinds = map[int]error{
+	http.StatusNotFound:           ErrNotFound,
+	http.StatusForbidden:          ErrAccessDenied,
+	http.StatusPreconditionFailed: ErrPreconditionFailed,
+	http.StatusTooManyRequests:    ErrThrottled,
+}
+
+// apiError wraps err in an *APIError when it carries an S3 error response.
+// Other errors, including nil and context errors, are returned as they are.
+func apiError(err error) error {
+	var ae smithy.APIError
+	if err == nil || errors.As(err, new(*APIError)) || !errors.As(err, &ae) {
+		return err
+	}
+
+	out := &APIError{
+		Kind:    errorKinds[ae.ErrorCode()],
+		Code:    ae.ErrorCode(),
+		Message: ae.ErrorMessage(),
+		Err:     err,
+	}
+
+	var re *smithyhttp.ResponseError
+	if errors.As(err, &re) {
+		out.StatusCode = re.HTTPStatusCode()
+	}
+	if out.Kind == nil {
+		out.Kind = statusKinds[out.StatusCode]
+	}
+
+	return out
+}
@@ -0,0 +1,156 @@
+package s3_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/smithy-go"
+
+	"github.com/itispx/goaws/s3"
+)
+
+func TestBucket_ValidationError(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	file := []byte("goaws")
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.UploadObject(&s3.BucketUploadObjectInput{
+		File: &file,
+	})
+	if err == nil || err.Error() != "empty 'Key' param" {
+		t.Fatal("invalid error message")
+	}
+
+	if !errors.Is(err, s3.ErrValidation) {
+		t.Error("expected the error to match ErrValidation")
+	}
+
+	var validationErr *s3.ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Field != "Key" {
+		t.Errorf("expected a validation error for 'Key', got %v", err)
+	}
+}
+
+func TestBucket_APIError(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	region := "us-east-1"
+	key := "key-name"
+
+	tests := []struct {
+		name     string
+		status   int
+		code     string
+		expected error
+		call     func(bct *s3.Bucket) error
+	}{
+		{
+			name:     "NoSuchKey",
+			status:   http.StatusNotFound,
+			code:     "NoSuchKey",
+			expected: s3.ErrNotFound,
+		},
+		{
+			name:     "AccessDenied",
+			status:   http.StatusForbidden,
+			code:     "AccessDenied",
+			expected: s3.ErrAccessDenied,
+		},
+		{
+			name:     "PreconditionFailed",
+			status:   http.StatusPreconditionFailed,
+			code:     "PreconditionFailed",
+			expected: s3.ErrPreconditionFailed,
+		},
+		{
+			name:     "SlowDown",
+			status:   http.StatusServiceUnavailable,
+			code:     "SlowDown",
+			expected: s3.ErrThrottled,
+		},
+		{
+			name:     "BucketAlreadyExists",
+			status:   http.StatusConflict,
+			code:     "BucketAlreadyExists",
+			expected: s3.ErrBucketAlreadyExists,
+			call: func(bct *s3.Bucket) error {
+				_, err := bct.Create(nil)
+				return err
+			},
+		},
+		{
+			name:     "HeadNotFound",
+			status:   http.StatusNotFound,
+			code:     "NotFound",
+			expected: s3.ErrNotFound,
+			call: func(bct *s3.Bucket) error {
+				_, err := bct.Download(&s3.BucketDownloadInput{
+					Key:    &key,
+					Writer: s3.NewWriteAtBuffer(nil),
+				})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			httpClient := &recordingClient{status: tt.status}
+			if tt.code != "NotFound" {
+				httpClient.body = "<Error><Code>" + tt.code + "</Code><Message>goaws</Message></Error>"
+			}
+
+			bct := s3.Bucket{
+				Name:   &name,
+				Region: &region,
+			}
+
+			_, err := bct.NewSession(
+				s3.WithStaticCredentials("AKID", "SECRET", ""),
+				s3.WithHTTPClient(httpClient),
+				s3.WithMaxAttempts(1),
+			)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			call := tt.call
+			if call == nil {
+				call = func(bct *s3.Bucket) error {
+					_, err := bct.GetObject(&s3.BucketGetObjectInput{
+						Key: &key,
+					})
+					return err
+				}
+			}
+
+			err = call(&bct)
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, err)
+			}
+
+			var apiErr *s3.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an APIError, got %v", err)
+			}
+			if apiErr.Code != tt.code || apiErr.StatusCode != tt.status {
+				t.Errorf("expected %s (%d), got %s (%d)", tt.code, tt.status, apiErr.Code, apiErr.StatusCode)
+			}
+
+			var smithyErr smithy.APIError
+			if !errors.As(err, &smithyErr) {
+				t.Error("expected the SDK error to stay in the chain")
+			}
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type IterateObjectsInput struct {
+	Prefix *string
+
+	// Delimiter groups keys that share everything up to the next delimiter
+	// after Prefix into a single entry, like a directory.
+	Delimiter  *string
+	StartAfter *string
+
+	// Limit caps the number of entries yielded across all pages. Without it
+	// every page is fetched.
+	Limit *int
+}
+
+// ListedObject is an entry yielded by ObjectIterator. Common prefixes have
+// IsPrefix set and only Key filled in.
+type ListedObject struct {
+	types.Object
+	IsPrefix bool
+}
+
+// ObjectIterator walks every object in a bucket, fetching pages as they are
+// needed. Entries are yielded in key order with common prefixes in between.
+//
+//	it := bct.IterateObjects(input)
+//	for it.Next() {
+//		obj := it.Object()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type ObjectIterator struct {
+	ctx    context.Context
+	bucket *Bucket
+	params s3.ListObjectsV2Input
+	limit  *int
+
+	page    []ListedObject
+	current ListedObject
+	count   int
+	done    bool
+	err     error
+}
+
+// IterateObjects returns an iterator over the objects matching input.
+// Validation and request errors are reported by Err once Next returns false.
+func (b *Bucket) IterateObjects(input *IterateObjectsInput) *ObjectIterator {
+	return b.IterateObjectsContext(context.Background(), input)
+}
+
+// Iteration stops with ctx's error once it is done.
+func (b *Bucket) IterateObjectsContext(ctx context.Context, input *IterateObjectsInput) *ObjectIterator {
+	it := &ObjectIterator{
+		ctx:    ctx,
+		bucket: b,
+	}
+
+	if b.Name == nil || *b.Name == "" {
+		it.err = emptyParam("Name")
+		return it
+	}
+	if input != nil && input.Limit != nil && *input.Limit < 1 {
+		it.err = paramError("Limit", "'Limit' must be at least 1")
+		return it
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			it.err = err
+			return it
+		}
+	}
+
+	if input == nil {
+		input = &IterateObjectsInput{}
+	}
+
+	it.params = s3.ListObjectsV2Input{
+		Bucket:     b.Name,
+		Prefix:     input.Prefix,
+		Delimiter:  input.Delimiter,
+		StartAfter: input.StartAfter,
+	}
+	it.limit = input.Limit
+
+	return it
+}
+
+// Next advances to the next entry, fetching the next page when the current
+// one is used up. It returns false at the end or on error.
+func (it *ObjectIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.limit != nil && it.count >= *it.limit {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	for len(it.page) == 0 {
+		if it.done {
+			return false
+		}
+
+		if err := it.fetch(); err != nil {
+			it.err = err
+			return false
+		}
+	}
+
+	it.current = it.page[0]
+	it.page = it.page[1:]
+	it.count++
+
+	return true
+}
+
+// Object returns the entry Next advanced to.
+func (it *ObjectIterator) Object() ListedObject {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ObjectIterator) Err() error {
+	return it.err
+}
+
+func (it *ObjectIterator) fetch() error {
+	if it.limit != nil {
+		it.params.MaxKeys = aws.Int32(int32(min(*it.limit-it.count, 1000)))
+	}
+
+	page, err := it.bucket.Client.ListObjectsV2(it.ctx, &it.params, withoutAccelerate)
+	if err != nil {
+		return fmt.Errorf("failed to list objects: %w", apiError(err))
+	}
+
+	it.page = mergeListing(page.Contents, page.CommonPrefixes)
+
+	if !aws.ToBool(page.IsTruncated) {
+		it.done = true
+	}
+	it.params.ContinuationToken = page.NextContinuationToken
+
+	return nil
+}
+
+// mergeListing interleaves objects and common prefixes, which S3 returns
+// separately, in key order.
+func mergeListing(objects []types.Object, prefixes []types.CommonPrefix) []ListedObject {
+	out := make([]ListedObject, 0, len(objects)+len(prefixes))
+
+	i, j := 0, 0
+	for i < len(objects) || j < len(prefixes) {
+		if j == len(prefixes) || (i < len(objects) && aws.ToString(objects[i].Key) < aws.ToString(prefixes[j].Prefix)) {
+			out = append(out, ListedObject{Object: objects[i]})
+			i++
+			continue
+		}
+
+		out = append(out, ListedObject{
+			Object:   types.Object{Key: prefixes[j].Prefix},
+			IsPrefix: true,
+		})
+		j++
+	}
+
+	return out
+}
+
+type IterateObjectVersionsInput struct {
+	Prefix *string
+
+	// Delimiter groups keys that share everything up to the next delimiter
+	// after Prefix into a single entry, like a directory.
+	Delimiter *string
+
+	// KeyMarker and VersionIdMarker start the listing after the given
+	// version, such as the next markers of a truncated ListObjectVersions.
+	KeyMarker       *string
+	VersionIdMarker *string
+
+	// Limit caps the number of entries yielded across all pages. Without it
+	// every page is fetched.
+	Limit *int
+}
+
+// ListedVersion is an entry yielded by VersionIterator. Delete markers have
+// IsDeleteMarker set and no ETag, Size or StorageClass. Common prefixes have
+// IsPrefix set and only Key filled in.
+type ListedVersion struct {
+	types.ObjectVersion
+	IsDeleteMarker bool
+	IsPrefix       bool
+}
+
+// VersionIterator walks every version and delete marker in a bucket,
+// fetching pages as they are needed. Entries are yielded in key order, each
+// key's newest first, with common prefixes in between.
+//
+//	it := bct.IterateObjectVersions(input)
+//	for it.Next() {
+//		v := it.Version()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type VersionIterator struct {
+	ctx    context.Context
+	bucket *Bucket
+	params s3.ListObjectVersionsInput
+	limit  *int
+
+	page    []ListedVersion
+	current ListedVersion
+	count   int
+	done    bool
+	err     error
+}
+
+// IterateObjectVersions returns an iterator over the versions matching
+// input. Validation and request errors are reported by Err once Next returns
+// false.
+func (b *Bucket) IterateObjectVersions(input *IterateObjectVersionsInput) *VersionIterator {
+	return b.IterateObjectVersionsContext(context.Background(), input)
+}
+
+// Iteration stops with ctx's error once it is done.
+func (b *Bucket) IterateObjectVersionsContext(ctx context.Context, input *IterateObjectVersionsInput) *VersionIterator {
+	it := &VersionIterator{
+		ctx:    ctx,
+		bucket: b,
+	}
+
+	if b.Name == nil || *b.Name == "" {
+		it.err = emptyParam("Name")
+		return it
+	}
+	if input != nil && input.Limit != nil && *input.Limit < 1 {
+		it.err = paramError("Limit", "'Limit' must be at least 1")
+		return it
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			it.err = err
+			return it
+		}
+	}
+
+	if input == nil {
+		input = &IterateObjectVersionsInput{}
+	}
+
+	it.params = s3.ListObjectVersionsInput{
+		Bucket:          b.Name,
+		Prefix:          input.Prefix,
+		Delimiter:       input.Delimiter,
+		KeyMarker:       input.KeyMarker,
+		VersionIdMarker: input.VersionIdMarker,
+	}
+	it.limit = input.Limit
+
+	return it
+}
+
+// Next advances to the next entry, fetching the next page when the current
+// one is used up. It returns false at the end or on error.
+func (it *VersionIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.limit != nil && it.count >= *it.limit {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	for len(it.page) == 0 {
+		if it.done {
+			return false
+		}
+
+		if err := it.fetch(); err != nil {
+			it.err = err
+			return false
+		}
+	}
+
+	it.current = it.page[0]
+	it.page = it.page[1:]
+	it.count++
+
+	return true
+}
+
+// Version returns the entry Next advanced to.
+func (it *VersionIterator) Version() ListedVersion {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *VersionIterator) Err() error {
+	return it.err
+}
+
+func (it *VersionIterator) fetch() error {
+	if it.limit != nil {
+		it.params.MaxKeys = aws.Int32(int32(min(*it.limit-it.count, 1000)))
+	}
+
+	page, err := it.bucket.Client.ListObjectVersions(it.ctx, &it.params, withoutAccelerate)
+	if err != nil {
+		return fmt.Errorf("failed to list object versions: %w", apiError(err))
+	}
+
+	it.page = mergeVersions(page.Versions, page.DeleteMarkers, page.CommonPrefixes)
+
+	if !aws.ToBool(page.IsTruncated) {
+		it.done = true
+	}
+	it.params.KeyMarker = page.NextKeyMarker
+	it.params.VersionIdMarker = page.NextVersionIdMarker
+
+	return nil
+}
+
+// mergeVersions interleaves versions, delete markers and common prefixes,
+// which S3 returns separately, in key order and each key's newest first.
+func mergeVersions(versions []types.ObjectVersion, markers []types.DeleteMarkerEntry, prefixes []types.CommonPrefix) []ListedVersion {
+	out := make([]ListedVersion, 0, len(versions)+len(markers)+len(prefixes))
+
+	for _, v := range versions {
+		out = append(out, ListedVersion{ObjectVersion: v})
+	}
+	for _, m := range markers {
+		out = append(out, ListedVersion{
+			ObjectVersion: types.ObjectVersion{
+				Key:          m.Key,
+				VersionId:    m.VersionId,
+				IsLatest:     m.IsLatest,
+				LastModified: m.LastModified,
+				Owner:        m.Owner,
+			},
+			IsDeleteMarker: true,
+		})
+	}
+	for _, p := range prefixes {
+		out = append(out, ListedVersion{
+			ObjectVersion: types.ObjectVersion{Key: p.Prefix},
+			IsPrefix:      true,
+		})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		ki, kj := aws.ToString(out[i].Key), aws.ToString(out[j].Key)
+		if ki != kj {
+			return ki < kj
+		}
+		// Listings can report the same time for versions made within a
+		// second of each other.
+		if li, lj := aws.ToBool(out[i].IsLatest), aws.ToBool(out[j].IsLatest); li != lj {
+			return li
+		}
+
+		return aws.ToTime(out[i].LastModified).After(aws.ToTime(out[j].LastModified))
+	})
+
+	return out
+}
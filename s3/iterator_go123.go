@@ -0,0 +1,53 @@
+//go:build go1.23
+
+package s3
+
+import (
+	"context"
+	"iter"
+)
+
+// Objects returns a sequence of the objects matching input for use with
+// range. An error ends the sequence and is yielded with a zero ListedObject.
+func (b *Bucket) Objects(input *IterateObjectsInput) iter.Seq2[ListedObject, error] {
+	return b.ObjectsContext(context.Background(), input)
+}
+
+func (b *Bucket) ObjectsContext(ctx context.Context, input *IterateObjectsInput) iter.Seq2[ListedObject, error] {
+	return func(yield func(ListedObject, error) bool) {
+		it := b.IterateObjectsContext(ctx, input)
+
+		for it.Next() {
+			if !yield(it.Object(), nil) {
+				return
+			}
+		}
+
+		if err := it.Err(); err != nil {
+			yield(ListedObject{}, err)
+		}
+	}
+}
+
+// ObjectVersions returns a sequence of the versions matching input for use
+// with range. An error ends the sequence and is yielded with a zero
+// ListedVersion.
+func (b *Bucket) ObjectVersions(input *IterateObjectVersionsInput) iter.Seq2[ListedVersion, error] {
+	return b.ObjectVersionsContext(context.Background(), input)
+}
+
+func (b *Bucket) ObjectVersionsContext(ctx context.Context, input *IterateObjectVersionsInput) iter.Seq2[ListedVersion, error] {
+	return func(yield func(ListedVersion, error) bool) {
+		it := b.IterateObjectVersionsContext(ctx, input)
+
+		for it.Next() {
+			if !yield(it.Version(), nil) {
+				return
+			}
+		}
+
+		if err := it.Err(); err != nil {
+			yield(ListedVersion{}, err)
+		}
+	}
+}
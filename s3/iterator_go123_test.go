@@ -0,0 +1,69 @@
+//go:build go1.23
+
+package s3_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/itispx/goaws/s3"
+)
+
+func TestBucket_Objects(t *testing.T) {
+	t.Parallel()
+
+	client := &listingClient{keys: []string{"a", "b/1", "b/2", "c", "d"}, pageSize: 2}
+	bct := newListingBucket(t, client)
+
+	var got []string
+
+	for obj, err := range bct.Objects(&s3.IterateObjectsInput{Delimiter: aws.String("/")}) {
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		got = append(got, *obj.Key)
+		if len(got) == 3 {
+			break
+		}
+	}
+
+	if len(got) != 3 || got[0] != "a" || got[1] != "b/" || got[2] != "c" {
+		t.Errorf("unexpected objects %v", got)
+	}
+	if client.requests != 2 {
+		t.Errorf("expected 2 requests, got %d", client.requests)
+	}
+}
+
+func TestBucket_ObjectVersions(t *testing.T) {
+	t.Parallel()
+
+	var markers []string
+
+	bct := s3.Bucket{
+		Name:   aws.String("bucket-name"),
+		Client: newVersionsMock(&markers),
+	}
+
+	var got []string
+
+	for v, err := range bct.ObjectVersions(nil) {
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		got = append(got, aws.ToString(v.Key))
+		if len(got) == 3 {
+			break
+		}
+	}
+
+	if len(got) != 3 || got[0] != "a" || got[2] != "a" {
+		t.Errorf("unexpected versions %v", got)
+	}
+	if len(markers) != 1 {
+		t.Errorf("expected 1 request, got %d", len(markers))
+	}
+}
@@ -0,0 +1,380 @@
+package s3_test
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/itispx/goaws/s3"
+	"github.com/itispx/goaws/s3/s3test"
+)
+
+// listingClient answers ListObjectsV2 requests from keys, at most pageSize
+// entries per page.
+type listingClient struct {
+	keys     []string
+	pageSize int
+	requests int
+}
+
+func (c *listingClient) Do(req *http.Request) (*http.Response, error) {
+	c.requests++
+
+	q := req.URL.Query()
+	prefix, delimiter := q.Get("prefix"), q.Get("delimiter")
+
+	after := q.Get("start-after")
+	if token := q.Get("continuation-token"); token != "" {
+		after = token
+	}
+
+	maxKeys := c.pageSize
+	if v, err := strconv.Atoi(q.Get("max-keys")); err == nil && v < maxKeys {
+		maxKeys = v
+	}
+
+	type entry struct {
+		Key    string `xml:",omitempty"`
+		Prefix string `xml:",omitempty"`
+	}
+
+	result := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		IsTruncated           bool
+		NextContinuationToken string  `xml:",omitempty"`
+		Contents              []entry `xml:"Contents"`
+		CommonPrefixes        []entry `xml:"CommonPrefixes"`
+	}{}
+
+	keys := append([]string(nil), c.keys...)
+	sort.Strings(keys)
+
+	seen := map[string]bool{}
+	count := 0
+
+	for _, k := range keys {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+
+		name, isPrefix := k, false
+		if delimiter != "" {
+			if i := strings.Index(k[len(prefix):], delimiter); i >= 0 {
+				name, isPrefix = k[:len(prefix)+i+len(delimiter)], true
+			}
+		}
+		if name <= after || seen[name] {
+			continue
+		}
+
+		if count == maxKeys {
+			result.IsTruncated = true
+			break
+		}
+
+		seen[name] = true
+		count++
+		result.NextContinuationToken = name
+
+		if isPrefix {
+			result.CommonPrefixes = append(result.CommonPrefixes, entry{Prefix: name})
+		} else {
+			result.Contents = append(result.Contents, entry{Key: name})
+		}
+	}
+
+	if !result.IsTruncated {
+		result.NextContinuationToken = ""
+	}
+
+	body, err := xml.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(string(body))),
+		Request:    req,
+	}, nil
+}
+
+func newListingBucket(t *testing.T, client *listingClient) *s3.Bucket {
+	name := "bucket-name"
+	region := "us-east-1"
+
+	bct := &s3.Bucket{
+		Name:   &name,
+		Region: &region,
+	}
+
+	_, err := bct.NewSession(s3.WithStaticCredentials("AKID", "SECRET", ""), s3.WithHTTPClient(client))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return bct
+}
+
+func TestBucket_IterateObjects(t *testing.T) {
+	t.Parallel()
+
+	keys := []string{"a", "b/1", "b/2", "c", "d/1", "e", "f/x/1", "g"}
+
+	tests := []struct {
+		name     string
+		input    *s3.IterateObjectsInput
+		expected []string
+		requests int
+	}{
+		{
+			name:     "All",
+			input:    nil,
+			expected: keys,
+			requests: 3,
+		},
+		{
+			name:     "Delimiter",
+			input:    &s3.IterateObjectsInput{Delimiter: aws.String("/")},
+			expected: []string{"a", "b/", "c", "d/", "e", "f/", "g"},
+			requests: 3,
+		},
+		{
+			name:     "Prefix",
+			input:    &s3.IterateObjectsInput{Prefix: aws.String("f/"), Delimiter: aws.String("/")},
+			expected: []string{"f/x/"},
+			requests: 1,
+		},
+		{
+			name:     "StartAfter",
+			input:    &s3.IterateObjectsInput{StartAfter: aws.String("c")},
+			expected: []string{"d/1", "e", "f/x/1", "g"},
+			requests: 2,
+		},
+		{
+			name:     "Limit",
+			input:    &s3.IterateObjectsInput{Limit: aws.Int(4)},
+			expected: []string{"a", "b/1", "b/2", "c"},
+			requests: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &listingClient{keys: keys, pageSize: 3}
+			bct := newListingBucket(t, client)
+
+			var got []string
+
+			it := bct.IterateObjects(tt.input)
+			for it.Next() {
+				obj := it.Object()
+				if obj.IsPrefix != strings.HasSuffix(*obj.Key, "/") {
+					t.Errorf("unexpected IsPrefix for '%s'", *obj.Key)
+				}
+
+				got = append(got, *obj.Key)
+			}
+			if err := it.Err(); err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+			if client.requests != tt.requests {
+				t.Errorf("expected %d requests, got %d", tt.requests, client.requests)
+			}
+		})
+	}
+}
+
+func TestBucket_IterateObjectsCanceled(t *testing.T) {
+	t.Parallel()
+
+	client := &listingClient{keys: []string{"a", "b", "c", "d"}, pageSize: 2}
+	bct := newListingBucket(t, client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	it := bct.IterateObjectsContext(ctx, nil)
+	if !it.Next() {
+		t.Fatal(it.Err())
+	}
+
+	cancel()
+
+	if it.Next() {
+		t.Error("expected the iteration to stop")
+	}
+	if !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("expected a cancellation error, got %v", it.Err())
+	}
+	if client.requests != 1 {
+		t.Errorf("expected 1 request, got %d", client.requests)
+	}
+}
+
+func TestBucket_IterateObjectsInvalidLimit(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	it := bct.IterateObjects(&s3.IterateObjectsInput{Limit: aws.Int(0)})
+	if it.Next() {
+		t.Error("expected no entries")
+	}
+	if it.Err() == nil || it.Err().Error() != "'Limit' must be at least 1" {
+		t.Error("invalid error message")
+	}
+}
+
+// newVersionsMock answers ListObjectVersions with two pages: versions and a
+// delete marker of "a" made within the same second, the prefix "b/", and
+// then the only version of "c".
+func newVersionsMock(markers *[]string) *s3test.Mock {
+	now := aws.Time(time.Now().Truncate(time.Second))
+	earlier := aws.Time(now.Add(-time.Hour))
+
+	pages := []*awss3.ListObjectVersionsOutput{
+		{
+			Versions: []types.ObjectVersion{
+				{Key: aws.String("a"), VersionId: aws.String("2"), LastModified: now},
+				{Key: aws.String("a"), VersionId: aws.String("1"), LastModified: earlier},
+			},
+			DeleteMarkers: []types.DeleteMarkerEntry{
+				{Key: aws.String("a"), VersionId: aws.String("3"), LastModified: now, IsLatest: aws.Bool(true)},
+			},
+			CommonPrefixes:      []types.CommonPrefix{{Prefix: aws.String("b/")}},
+			IsTruncated:         aws.Bool(true),
+			NextKeyMarker:       aws.String("b/"),
+			NextVersionIdMarker: aws.String(""),
+		},
+		{
+			Versions: []types.ObjectVersion{
+				{Key: aws.String("c"), VersionId: aws.String("1"), LastModified: earlier, IsLatest: aws.Bool(true)},
+			},
+		},
+	}
+
+	mock := &s3test.Mock{}
+	mock.OnFunc("ListObjectVersions", func(input any) (any, error) {
+		*markers = append(*markers, aws.ToString(input.(*awss3.ListObjectVersionsInput).KeyMarker))
+
+		page := pages[0]
+		pages = pages[1:]
+
+		return page, nil
+	})
+
+	return mock
+}
+
+func TestBucket_IterateObjectVersions(t *testing.T) {
+	t.Parallel()
+
+	var markers []string
+
+	bct := s3.Bucket{
+		Name:   aws.String("bucket-name"),
+		Client: newVersionsMock(&markers),
+	}
+
+	var got []string
+
+	it := bct.IterateObjectVersions(nil)
+	for it.Next() {
+		v := it.Version()
+
+		entry := aws.ToString(v.Key)
+		switch {
+		case v.IsPrefix:
+		case v.IsDeleteMarker:
+			entry += "@marker"
+		default:
+			entry += "@" + aws.ToString(v.VersionId)
+		}
+
+		got = append(got, entry)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := "a@marker,a@2,a@1,b/,c@1"
+
+	if strings.Join(got, ",") != expected {
+		t.Errorf("expected %s, got %v", expected, got)
+	}
+	if strings.Join(markers, ",") != ",b/" {
+		t.Errorf("unexpected key markers %q", markers)
+	}
+}
+
+func TestBucket_IterateObjectVersionsLimit(t *testing.T) {
+	t.Parallel()
+
+	var markers []string
+
+	mock := newVersionsMock(&markers)
+	bct := s3.Bucket{
+		Name:   aws.String("bucket-name"),
+		Client: mock,
+	}
+
+	count := 0
+
+	it := bct.IterateObjectVersions(&s3.IterateObjectVersionsInput{Limit: aws.Int(2)})
+	for it.Next() {
+		count++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if count != 2 {
+		t.Errorf("expected 2 entries, got %d", count)
+	}
+
+	calls := mock.CallsTo("ListObjectVersions")
+	if len(calls) != 1 || aws.ToInt32(calls[0].Input.(*awss3.ListObjectVersionsInput).MaxKeys) != 2 {
+		t.Errorf("expected a single request for 2 keys, got %d", len(calls))
+	}
+}
+
+func TestBucket_IterateObjectVersionsInvalidLimit(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	it := bct.IterateObjectVersions(&s3.IterateObjectVersionsInput{Limit: aws.Int(0)})
+	if it.Next() {
+		t.Error("expected no entries")
+	}
+	if it.Err() == nil || it.Err().Error() != "'Limit' must be at least 1" {
+		t.Error("invalid error message")
+	}
+}
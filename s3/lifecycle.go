@@ -0,0 +1,354 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+const MaxLifecycleRules = 1000
+
+// transitionOrder ranks storage classes from warmest to coldest. Lifecycle
+// transitions may only move objects towards colder classes.
+var transitionOrder = map[types.TransitionStorageClass]int{
+	types.TransitionStorageClassStandardIa:         1,
+	types.TransitionStorageClassIntelligentTiering: 2,
+	types.TransitionStorageClassOnezoneIa:          3,
+	types.TransitionStorageClassGlacierIr:          4,
+	types.TransitionStorageClassGlacier:            5,
+	types.TransitionStorageClassDeepArchive:        6,
+}
+
+// minTransitionDays holds the classes S3 refuses to transition into before
+// objects are a certain age.
+var minTransitionDays = map[types.TransitionStorageClass]int32{
+	types.TransitionStorageClassStandardIa: 30,
+	types.TransitionStorageClassOnezoneIa:  30,
+}
+
+// LifecycleRule builds one rule of a bucket lifecycle configuration. Setters
+// can be chained; problems are reported by Build.
+//
+//	rule := s3.NewLifecycleRule("logs").
+//		WithPrefix("logs/").
+//		Transition(30, types.TransitionStorageClassStandardIa).
+//		Transition(90, types.TransitionStorageClassGlacier).
+//		Expire(365)
+type LifecycleRule struct {
+	id                   string
+	prefix               string
+	tags                 map[string]string
+	disabled             bool
+	transitions          []types.Transition
+	expiration           *int32
+	noncurrentExpiration *int32
+	abortIncomplete      *int32
+}
+
+func NewLifecycleRule(id string) *LifecycleRule {
+	return &LifecycleRule{
+		id:   id,
+		tags: map[string]string{},
+	}
+}
+
+// WithPrefix limits the rule to keys starting with prefix.
+func (r *LifecycleRule) WithPrefix(prefix string) *LifecycleRule {
+	r.prefix = prefix
+	return r
+}
+
+// WithTag limits the rule to objects carrying the tag. Several tags must all
+// match.
+func (r *LifecycleRule) WithTag(key, value string) *LifecycleRule {
+	r.tags[key] = value
+	return r
+}
+
+// Disabled stores the rule without applying it.
+func (r *LifecycleRule) Disabled() *LifecycleRule {
+	r.disabled = true
+	return r
+}
+
+// Transition moves objects to class once they are days old. Transitions
+// must be added from the warmest class to the coldest.
+func (r *LifecycleRule) Transition(days int32, class types.TransitionStorageClass) *LifecycleRule {
+	r.transitions = append(r.transitions, types.Transition{
+		Days:         aws.Int32(days),
+		StorageClass: class,
+	})
+	return r
+}
+
+// Expire deletes objects once they are days old. In a versioned bucket this
+// adds a delete marker instead.
+func (r *LifecycleRule) Expire(days int32) *LifecycleRule {
+	r.expiration = aws.Int32(days)
+	return r
+}
+
+// ExpireNoncurrent permanently deletes versions days after they stop being
+// the current version.
+func (r *LifecycleRule) ExpireNoncurrent(days int32) *LifecycleRule {
+	r.noncurrentExpiration = aws.Int32(days)
+	return r
+}
+
+// AbortIncompleteUploads aborts multipart uploads that have not completed
+// days after they were started.
+func (r *LifecycleRule) AbortIncompleteUploads(days int32) *LifecycleRule {
+	r.abortIncomplete = aws.Int32(days)
+	return r
+}
+
+// Build validates the rule and converts it to the SDK type.
+func (r *LifecycleRule) Build() (types.LifecycleRule, error) {
+	if err := r.validate(); err != nil {
+		return types.LifecycleRule{}, fmt.Errorf("invalid lifecycle rule '%s': %w", r.id, err)
+	}
+
+	rule := types.LifecycleRule{
+		ID:          aws.String(r.id),
+		Status:      types.ExpirationStatusEnabled,
+		Filter:      r.filter(),
+		Transitions: r.transitions,
+	}
+
+	if r.disabled {
+		rule.Status = types.ExpirationStatusDisabled
+	}
+	if r.expiration != nil {
+		rule.Expiration = &types.LifecycleExpiration{Days: r.expiration}
+	}
+	if r.noncurrentExpiration != nil {
+		rule.NoncurrentVersionExpiration = &types.NoncurrentVersionExpiration{NoncurrentDays: r.noncurrentExpiration}
+	}
+	if r.abortIncomplete != nil {
+		rule.AbortIncompleteMultipartUpload = &types.AbortIncompleteMultipartUpload{DaysAfterInitiation: r.abortIncomplete}
+	}
+
+	return rule, nil
+}
+
+func (r *LifecycleRule) validate() error {
+	if r.id == "" {
+		return emptyParam("ID")
+	}
+	if len(r.id) > 255 {
+		return paramError("ID", "'ID' must be at most 255 characters")
+	}
+	if len(r.transitions) == 0 && r.expiration == nil && r.noncurrentExpiration == nil && r.abortIncomplete == nil {
+		return paramError("Rules", "rule has no actions")
+	}
+
+	for _, d := range []struct {
+		name string
+		days *int32
+	}{
+		{"Expire", r.expiration},
+		{"ExpireNoncurrent", r.noncurrentExpiration},
+		{"AbortIncompleteUploads", r.abortIncomplete},
+	} {
+		if d.days != nil && *d.days < 1 {
+			return paramError(d.name, "'%s' days must be at least 1", d.name)
+		}
+	}
+
+	if r.abortIncomplete != nil && len(r.tags) > 0 {
+		return paramError("AbortIncompleteUploads", "'AbortIncompleteUploads' cannot be combined with tag filters")
+	}
+
+	var prev *types.Transition
+	for i := range r.transitions {
+		t := &r.transitions[i]
+		days := aws.ToInt32(t.Days)
+
+		rank, ok := transitionOrder[t.StorageClass]
+		if !ok {
+			return paramError("Transition", "unsupported transition storage class '%s'", t.StorageClass)
+		}
+		if days < 0 {
+			return paramError("Transition", "'Transition' days must not be negative")
+		}
+		if days < minTransitionDays[t.StorageClass] {
+			return paramError("Transition", "transition to %s requires at least %d days", t.StorageClass, minTransitionDays[t.StorageClass])
+		}
+
+		if prev != nil {
+			if rank <= transitionOrder[prev.StorageClass] {
+				return paramError("Transition", "cannot transition from %s to %s", prev.StorageClass, t.StorageClass)
+			}
+			if days <= aws.ToInt32(prev.Days) {
+				return paramError("Transition", "transition to %s after %d days must come later than transition to %s after %d days", t.StorageClass, days, prev.StorageClass, aws.ToInt32(prev.Days))
+			}
+		}
+
+		prev = t
+	}
+
+	if prev != nil && r.expiration != nil && *r.expiration <= aws.ToInt32(prev.Days) {
+		return paramError("Expire", "expiration after %d days must come later than the last transition after %d days", *r.expiration, aws.ToInt32(prev.Days))
+	}
+
+	return nil
+}
+
+func (r *LifecycleRule) filter() types.LifecycleRuleFilter {
+	if len(r.tags) == 0 {
+		return &types.LifecycleRuleFilterMemberPrefix{Value: r.prefix}
+	}
+
+	tags := make([]types.Tag, 0, len(r.tags))
+	for k, v := range r.tags {
+		tags = append(tags, types.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return *tags[i].Key < *tags[j].Key
+	})
+
+	if len(tags) == 1 && r.prefix == "" {
+		return &types.LifecycleRuleFilterMemberTag{Value: tags[0]}
+	}
+
+	return &types.LifecycleRuleFilterMemberAnd{
+		Value: types.LifecycleRuleAndOperator{
+			Prefix: aws.String(r.prefix),
+			Tags:   tags,
+		},
+	}
+}
+
+type BucketPutLifecycleInput struct {
+	Rules *[]*LifecycleRule
+}
+
+// PutLifecycle replaces the bucket's lifecycle configuration. Every rule is
+// validated before the request is sent.
+func (b *Bucket) PutLifecycle(input *BucketPutLifecycleInput) (*s3.PutBucketLifecycleConfigurationOutput, error) {
+	return b.PutLifecycleContext(context.Background(), input)
+}
+
+func (b *Bucket) PutLifecycleContext(ctx context.Context, input *BucketPutLifecycleInput) (*s3.PutBucketLifecycleConfigurationOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if *input == (BucketPutLifecycleInput{}) {
+		return nil, emptyInput()
+	}
+	if input.Rules == nil || len(*input.Rules) == 0 {
+		return nil, emptyParam("Rules")
+	}
+	if len(*input.Rules) > MaxLifecycleRules {
+		return nil, paramError("Rules", "'Rules' must have at most %d rules", MaxLifecycleRules)
+	}
+
+	rules := make([]types.LifecycleRule, 0, len(*input.Rules))
+	ids := map[string]bool{}
+
+	for _, r := range *input.Rules {
+		if r == nil {
+			return nil, paramError("Rules", "nil rule in 'Rules' param")
+		}
+
+		rule, err := r.Build()
+		if err != nil {
+			return nil, err
+		}
+
+		if ids[r.id] {
+			return nil, paramError("ID", "duplicate lifecycle rule ID '%s'", r.id)
+		}
+		ids[r.id] = true
+
+		rules = append(rules, rule)
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket: b.Name,
+		LifecycleConfiguration: &types.BucketLifecycleConfiguration{
+			Rules: rules,
+		},
+	}, withoutAccelerate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put bucket lifecycle: %w", apiError(err))
+	}
+
+	return out, nil
+}
+
+// GetLifecycle returns the bucket's lifecycle rules, or none when the bucket
+// has no lifecycle configuration.
+func (b *Bucket) GetLifecycle() ([]types.LifecycleRule, error) {
+	return b.GetLifecycleContext(context.Background())
+}
+
+func (b *Bucket) GetLifecycleContext(ctx context.Context) ([]types.LifecycleRule, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: b.Name,
+	}, withoutAccelerate)
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchLifecycleConfiguration" {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to get bucket lifecycle: %w", apiError(err))
+	}
+
+	return out.Rules, nil
+}
+
+// DeleteLifecycle removes every lifecycle rule from the bucket.
+func (b *Bucket) DeleteLifecycle() (*s3.DeleteBucketLifecycleOutput, error) {
+	return b.DeleteLifecycleContext(context.Background())
+}
+
+func (b *Bucket) DeleteLifecycleContext(ctx context.Context) (*s3.DeleteBucketLifecycleOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.DeleteBucketLifecycle(ctx, &s3.DeleteBucketLifecycleInput{
+		Bucket: b.Name,
+	}, withoutAccelerate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete bucket lifecycle: %w", apiError(err))
+	}
+
+	return out, nil
+}
@@ -0,0 +1,240 @@
+package s3_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/itispx/goaws/s3"
+)
+
+func TestBucket_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	rules, err := bct.GetLifecycle()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(rules) != 0 {
+		t.Errorf("expected no rules, got %d", len(rules))
+	}
+
+	input := []*s3.LifecycleRule{
+		s3.NewLifecycleRule("logs").
+			WithPrefix("logs/").
+			Transition(30, types.TransitionStorageClassStandardIa).
+			Transition(90, types.TransitionStorageClassGlacier).
+			Expire(365),
+		s3.NewLifecycleRule("uploads").
+			AbortIncompleteUploads(7),
+	}
+
+	_, err = bct.PutLifecycle(&s3.BucketPutLifecycleInput{
+		Rules: &input,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	rules, err = bct.GetLifecycle()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rules))
+	}
+
+	if *rules[0].ID != "logs" || len(rules[0].Transitions) != 2 || *rules[0].Expiration.Days != 365 {
+		t.Error("unexpected 'logs' rule")
+	}
+
+	_, err = bct.DeleteLifecycle()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	t.Cleanup(func() {
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func TestLifecycleRule_Build(t *testing.T) {
+	t.Parallel()
+
+	rule, err := s3.NewLifecycleRule("archive").
+		WithPrefix("archive/").
+		WithTag("team", "storage").
+		Transition(30, types.TransitionStorageClassStandardIa).
+		Transition(180, types.TransitionStorageClassDeepArchive).
+		ExpireNoncurrent(30).
+		Disabled().
+		Build()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if rule.Status != types.ExpirationStatusDisabled {
+		t.Errorf("expected status '%s', got '%s'", types.ExpirationStatusDisabled, rule.Status)
+	}
+
+	filter, ok := rule.Filter.(*types.LifecycleRuleFilterMemberAnd)
+	if !ok {
+		t.Fatalf("expected an 'And' filter, got %T", rule.Filter)
+	}
+
+	if *filter.Value.Prefix != "archive/" || len(filter.Value.Tags) != 1 {
+		t.Error("unexpected filter")
+	}
+
+	if *rule.NoncurrentVersionExpiration.NoncurrentDays != 30 {
+		t.Error("unexpected noncurrent expiration")
+	}
+}
+
+func TestLifecycleRule_BuildInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		rule *s3.LifecycleRule
+		err  string
+	}{
+		{
+			name: "EmptyID",
+			rule: s3.NewLifecycleRule("").Expire(1),
+			err:  "invalid lifecycle rule '': empty 'ID' param",
+		},
+		{
+			name: "NoActions",
+			rule: s3.NewLifecycleRule("rule").WithPrefix("logs/"),
+			err:  "invalid lifecycle rule 'rule': rule has no actions",
+		},
+		{
+			name: "ZeroExpiration",
+			rule: s3.NewLifecycleRule("rule").Expire(0),
+			err:  "invalid lifecycle rule 'rule': 'Expire' days must be at least 1",
+		},
+		{
+			name: "NegativeTransition",
+			rule: s3.NewLifecycleRule("rule").Transition(-5, types.TransitionStorageClassGlacierIr),
+			err:  "invalid lifecycle rule 'rule': 'Transition' days must not be negative",
+		},
+		{
+			name: "EarlyInfrequentAccess",
+			rule: s3.NewLifecycleRule("rule").Transition(10, types.TransitionStorageClassStandardIa),
+			err:  "invalid lifecycle rule 'rule': transition to STANDARD_IA requires at least 30 days",
+		},
+		{
+			name: "TransitionDaysBackwards",
+			rule: s3.NewLifecycleRule("rule").
+				Transition(90, types.TransitionStorageClassStandardIa).
+				Transition(60, types.TransitionStorageClassGlacier),
+			err: "invalid lifecycle rule 'rule': transition to GLACIER after 60 days must come later than transition to STANDARD_IA after 90 days",
+		},
+		{
+			name: "TransitionToWarmerClass",
+			rule: s3.NewLifecycleRule("rule").
+				Transition(30, types.TransitionStorageClassGlacier).
+				Transition(60, types.TransitionStorageClassStandardIa),
+			err: "invalid lifecycle rule 'rule': cannot transition from GLACIER to STANDARD_IA",
+		},
+		{
+			name: "ExpireBeforeTransition",
+			rule: s3.NewLifecycleRule("rule").
+				Transition(30, types.TransitionStorageClassGlacier).
+				Expire(30),
+			err: "invalid lifecycle rule 'rule': expiration after 30 days must come later than the last transition after 30 days",
+		},
+		{
+			name: "AbortWithTags",
+			rule: s3.NewLifecycleRule("rule").
+				WithTag("team", "storage").
+				AbortIncompleteUploads(7),
+			err: "invalid lifecycle rule 'rule': 'AbortIncompleteUploads' cannot be combined with tag filters",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tt.rule.Build()
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("expected error '%s', got '%v'", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestBucket_PutLifecycleNilInput(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.PutLifecycle(nil)
+	if err == nil || err.Error() != "nil input" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_PutLifecycleEmptyRules(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	rules := []*s3.LifecycleRule{}
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.PutLifecycle(&s3.BucketPutLifecycleInput{
+		Rules: &rules,
+	})
+	if err == nil || err.Error() != "empty 'Rules' param" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_PutLifecycleDuplicateID(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	rules := []*s3.LifecycleRule{
+		s3.NewLifecycleRule("rule").Expire(1),
+		s3.NewLifecycleRule("rule").Expire(2),
+	}
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.PutLifecycle(&s3.BucketPutLifecycleInput{
+		Rules: &rules,
+	})
+	if err == nil || err.Error() != "duplicate lifecycle rule ID 'rule'" {
+		t.Error("invalid error message")
+	}
+}
@@ -0,0 +1,302 @@
+package s3
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// EnableAccessLogging delivers server access logs for the bucket to
+// targetBucket under prefix. The target bucket must be in the same region
+// and allow the logging service to write to it.
+func (b *Bucket) EnableAccessLogging(targetBucket, prefix string) (*s3.PutBucketLoggingOutput, error) {
+	return b.EnableAccessLoggingContext(context.Background(), targetBucket, prefix)
+}
+
+func (b *Bucket) EnableAccessLoggingContext(ctx context.Context, targetBucket, prefix string) (*s3.PutBucketLoggingOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if targetBucket == "" {
+		return nil, emptyParam("targetBucket")
+	}
+
+	return b.putLogging(ctx, &types.LoggingEnabled{
+		TargetBucket: aws.String(targetBucket),
+		TargetPrefix: aws.String(prefix),
+	})
+}
+
+// DisableAccessLogging stops delivering server access logs for the bucket.
+func (b *Bucket) DisableAccessLogging() (*s3.PutBucketLoggingOutput, error) {
+	return b.DisableAccessLoggingContext(context.Background())
+}
+
+func (b *Bucket) DisableAccessLoggingContext(ctx context.Context) (*s3.PutBucketLoggingOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+
+	return b.putLogging(ctx, nil)
+}
+
+func (b *Bucket) putLogging(ctx context.Context, logging *types.LoggingEnabled) (*s3.PutBucketLoggingOutput, error) {
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.PutBucketLogging(ctx, &s3.PutBucketLoggingInput{
+		Bucket: b.Name,
+		BucketLoggingStatus: &types.BucketLoggingStatus{
+			LoggingEnabled: logging,
+		},
+	}, withoutAccelerate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put bucket logging: %w", apiError(err))
+	}
+
+	return out, nil
+}
+
+// LoggingStatus returns where access logs are delivered, or nil when
+// access logging is disabled.
+func (b *Bucket) LoggingStatus() (*types.LoggingEnabled, error) {
+	return b.LoggingStatusContext(context.Background())
+}
+
+func (b *Bucket) LoggingStatusContext(ctx context.Context) (*types.LoggingEnabled, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.GetBucketLogging(ctx, &s3.GetBucketLoggingInput{
+		Bucket: b.Name,
+	}, withoutAccelerate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bucket logging: %w", apiError(err))
+	}
+
+	return out.LoggingEnabled, nil
+}
+
+// AccessLogRecord is one entry of an S3 server access log. Fields logged as
+// "-" are left empty or zero.
+type AccessLogRecord struct {
+	BucketOwner        string
+	Bucket             string
+	Time               time.Time
+	RemoteIP           string
+	Requester          string
+	RequestID          string
+	Operation          string
+	Key                string
+	RequestURI         string
+	HTTPStatus         int
+	ErrorCode          string
+	BytesSent          int64
+	ObjectSize         int64
+	TotalTime          time.Duration
+	TurnAroundTime     time.Duration
+	Referer            string
+	UserAgent          string
+	VersionID          string
+	HostID             string
+	SignatureVersion   string
+	CipherSuite        string
+	AuthenticationType string
+	HostHeader         string
+	TLSVersion         string
+	AccessPointARN     string
+	ACLRequired        bool
+}
+
+const (
+	accessLogTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+	// Fields up to the turn-around time are present in every log format;
+	// later ones were added over time.
+	minAccessLogFields = 15
+)
+
+// ParseAccessLogLine parses a single server access log line. Fields S3 adds
+// after the ones known here are ignored.
+func ParseAccessLogLine(line string) (*AccessLogRecord, error) {
+	fields, err := splitAccessLogLine(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) < minAccessLogFields {
+		return nil, fmt.Errorf("expected at least %d fields, got %d", minAccessLogFields, len(fields))
+	}
+
+	// Pad so that optional trailing fields read as "-".
+	for len(fields) < 26 {
+		fields = append(fields, "")
+	}
+
+	r := &AccessLogRecord{
+		BucketOwner:        fields[0],
+		Bucket:             fields[1],
+		RemoteIP:           fields[3],
+		Requester:          fields[4],
+		RequestID:          fields[5],
+		Operation:          fields[6],
+		Key:                fields[7],
+		RequestURI:         fields[8],
+		ErrorCode:          fields[10],
+		Referer:            fields[15],
+		UserAgent:          fields[16],
+		VersionID:          fields[17],
+		HostID:             fields[18],
+		SignatureVersion:   fields[19],
+		CipherSuite:        fields[20],
+		AuthenticationType: fields[21],
+		HostHeader:         fields[22],
+		TLSVersion:         fields[23],
+		AccessPointARN:     fields[24],
+		ACLRequired:        fields[25] == "Yes",
+	}
+
+	r.Time, err = time.Parse(accessLogTimeLayout, fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid time '%s': %w", fields[2], err)
+	}
+
+	if key, err := url.PathUnescape(r.Key); err == nil {
+		r.Key = key
+	}
+
+	status, err := accessLogInt(fields[9], "HTTP status")
+	if err != nil {
+		return nil, err
+	}
+	sent, err := accessLogInt(fields[11], "bytes sent")
+	if err != nil {
+		return nil, err
+	}
+	size, err := accessLogInt(fields[12], "object size")
+	if err != nil {
+		return nil, err
+	}
+	total, err := accessLogInt(fields[13], "total time")
+	if err != nil {
+		return nil, err
+	}
+	turnAround, err := accessLogInt(fields[14], "turn-around time")
+	if err != nil {
+		return nil, err
+	}
+
+	r.HTTPStatus = int(status)
+	r.BytesSent = sent
+	r.ObjectSize = size
+	r.TotalTime = time.Duration(total) * time.Millisecond
+	r.TurnAroundTime = time.Duration(turnAround) * time.Millisecond
+
+	return r, nil
+}
+
+// ParseAccessLog parses every line of a log file delivered by S3, skipping
+// blank lines.
+func ParseAccessLog(r io.Reader) ([]AccessLogRecord, error) {
+	var records []AccessLogRecord
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		record, err := ParseAccessLogLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		records = append(records, *record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read access log: %w", err)
+	}
+
+	return records, nil
+}
+
+// splitAccessLogLine splits a line on spaces, keeping [bracketed] and
+// "quoted" fields together and turning "-" into an empty field.
+func splitAccessLogLine(line string) ([]string, error) {
+	var fields []string
+
+	for i := 0; i < len(line); {
+		if line[i] == ' ' {
+			i++
+			continue
+		}
+
+		var field string
+		switch line[i] {
+		case '[', '"':
+			closing := byte(']')
+			if line[i] == '"' {
+				closing = '"'
+			}
+
+			end := strings.IndexByte(line[i+1:], closing)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated field at offset %d", i)
+			}
+
+			field = line[i+1 : i+1+end]
+			i += end + 2
+		default:
+			end := strings.IndexByte(line[i:], ' ')
+			if end < 0 {
+				end = len(line) - i
+			}
+
+			field = line[i : i+end]
+			i += end
+		}
+
+		if field == "-" {
+			field = ""
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+func accessLogInt(v, name string) (int64, error) {
+	if v == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s '%s'", name, v)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,159 @@
+package s3_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itispx/goaws/s3"
+)
+
+func TestBucket_AccessLogging(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	target, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	status, err := bct.LoggingStatus()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if status != nil {
+		t.Errorf("expected logging to be disabled, got %+v", status)
+	}
+
+	_, err = bct.EnableAccessLogging(target, "logs/")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	status, err = bct.LoggingStatus()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if status == nil || *status.TargetBucket != target || *status.TargetPrefix != "logs/" {
+		t.Errorf("unexpected logging status %+v", status)
+	}
+
+	_, err = bct.DisableAccessLogging()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	t.Cleanup(func() {
+		for _, name := range []string{bucket, target} {
+			err = deleteBucket(name, region)
+			if err != nil {
+				t.Errorf("cleanup fail: %s", err.Error())
+			}
+		}
+	})
+}
+
+func TestBucket_EnableAccessLoggingEmptyTarget(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.EnableAccessLogging("", "logs/")
+	if err == nil || err.Error() != "empty 'targetBucket' param" {
+		t.Error("invalid error message")
+	}
+}
+
+const accessLogLine = `79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be awsexamplebucket1 [06/Feb/2019:00:00:38 +0000] 192.0.2.3 79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be 3E57427F3EXAMPLE REST.GET.VERSIONING photos/my%20cat.jpg "GET /awsexamplebucket1?versioning HTTP/1.1" 200 - 113 - 7 6 "-" "S3Console/0.4" - s9lzHYrFp76ZVxRcpX9+5cjAnEH2ROuNkd2BHfIa6UkFVdtjf5mKR3/eTPFvsiP/XV/VLi31234= SigV4 ECDHE-RSA-AES128-GCM-SHA256 AuthHeader awsexamplebucket1.s3.us-west-1.amazonaws.com TLSV1.2 - Yes`
+
+func TestParseAccessLogLine(t *testing.T) {
+	t.Parallel()
+
+	r, err := s3.ParseAccessLogLine(accessLogLine)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := s3.AccessLogRecord{
+		BucketOwner:        "79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be",
+		Bucket:             "awsexamplebucket1",
+		Time:               time.Date(2019, time.February, 6, 0, 0, 38, 0, time.UTC),
+		RemoteIP:           "192.0.2.3",
+		Requester:          "79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be",
+		RequestID:          "3E57427F3EXAMPLE",
+		Operation:          "REST.GET.VERSIONING",
+		Key:                "photos/my cat.jpg",
+		RequestURI:         "GET /awsexamplebucket1?versioning HTTP/1.1",
+		HTTPStatus:         200,
+		BytesSent:          113,
+		TotalTime:          7 * time.Millisecond,
+		TurnAroundTime:     6 * time.Millisecond,
+		UserAgent:          "S3Console/0.4",
+		HostID:             "s9lzHYrFp76ZVxRcpX9+5cjAnEH2ROuNkd2BHfIa6UkFVdtjf5mKR3/eTPFvsiP/XV/VLi31234=",
+		SignatureVersion:   "SigV4",
+		CipherSuite:        "ECDHE-RSA-AES128-GCM-SHA256",
+		AuthenticationType: "AuthHeader",
+		HostHeader:         "awsexamplebucket1.s3.us-west-1.amazonaws.com",
+		TLSVersion:         "TLSV1.2",
+		ACLRequired:        true,
+	}
+
+	if !r.Time.Equal(expected.Time) {
+		t.Errorf("expected time %s, got %s", expected.Time, r.Time)
+	}
+	r.Time = expected.Time
+
+	if *r != expected {
+		t.Errorf("expected %+v, got %+v", expected, *r)
+	}
+}
+
+func TestParseAccessLog(t *testing.T) {
+	t.Parallel()
+
+	// Older logs end after the user agent.
+	short, _, _ := strings.Cut(accessLogLine, " - s9lz")
+
+	records, err := s3.ParseAccessLog(strings.NewReader(accessLogLine + "\n\n" + short + "\n"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	_, err = s3.ParseAccessLog(strings.NewReader(accessLogLine + "\nowner bucket [06/Feb/2019:00:00:38 +0000]\n"))
+	if err == nil || err.Error() != "line 2: expected at least 15 fields, got 3" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestParseAccessLogLineInvalidStatus(t *testing.T) {
+	t.Parallel()
+
+	line := strings.Replace(accessLogLine, `HTTP/1.1" 200`, `HTTP/1.1" OK`, 1)
+
+	_, err := s3.ParseAccessLogLine(line)
+	if err == nil || err.Error() != "invalid HTTP status 'OK'" {
+		t.Error("invalid error message")
+	}
+}
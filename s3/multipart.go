@@ -0,0 +1,265 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	MinPartSize        int64 = 5 * 1024 * 1024
+	MaxPartSize        int64 = 5 * 1024 * 1024 * 1024
+	MaxUploadParts           = 10000
+	DefaultPartSize          = MinPartSize
+	DefaultConcurrency       = 5
+)
+
+type BucketUploadMultipartInput struct {
+	Body        io.Reader
+	Key         *string
+	PartSize    *int64
+	Concurrency *int
+	Encryption  *Encryption
+	*s3.CreateMultipartUploadInput
+}
+
+// UploadMultipart streams Body to the bucket as a multipart upload. Body is
+// read in PartSize chunks and up to Concurrency parts are uploaded at once, so
+// the object size does not need to be known up front. The upload is aborted if
+// any part fails.
+func (b *Bucket) UploadMultipart(input *BucketUploadMultipartInput) (*s3.CompleteMultipartUploadOutput, string, error) {
+	return b.UploadMultipartContext(context.Background(), input)
+}
+
+func (b *Bucket) UploadMultipartContext(ctx context.Context, input *BucketUploadMultipartInput) (*s3.CompleteMultipartUploadOutput, string, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, "", emptyParam("Name")
+	}
+	if input == nil {
+		return nil, "", nilInput()
+	}
+	if *input == (BucketUploadMultipartInput{}) {
+		return nil, "", emptyInput()
+	}
+	if input.Body == nil {
+		return nil, "", emptyParam("Body")
+	}
+	if input.Key == nil || *input.Key == "" {
+		return nil, "", emptyParam("Key")
+	}
+
+	partSize, concurrency, err := multipartSettings(input.PartSize, input.Concurrency)
+	if err != nil {
+		return nil, "", err
+	}
+
+	enc, err := b.encryption(input.Encryption)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	if input.CreateMultipartUploadInput == nil {
+		input.CreateMultipartUploadInput = &s3.CreateMultipartUploadInput{}
+	}
+
+	input.CreateMultipartUploadInput.Key = input.Key
+	input.CreateMultipartUploadInput.Bucket = b.Name
+
+	enc.applyCreateMultipartUpload(input.CreateMultipartUploadInput)
+
+	created, err := b.Client.CreateMultipartUpload(ctx, input.CreateMultipartUploadInput)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create multipart upload: %w", apiError(err))
+	}
+
+	parts, err := b.uploadParts(ctx, input.Key, created.UploadId, input.Body, partSize, concurrency, enc)
+	if err != nil {
+		return nil, "", b.abortMultipartUpload(ctx, input.Key, created.UploadId, err)
+	}
+
+	complete := &s3.CompleteMultipartUploadInput{
+		Bucket:   b.Name,
+		Key:      input.Key,
+		UploadId: created.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: parts,
+		},
+	}
+	enc.applyCompleteMultipartUpload(complete)
+
+	out, err := b.Client.CompleteMultipartUpload(ctx, complete)
+	if err != nil {
+		return nil, "", b.abortMultipartUpload(ctx, input.Key, created.UploadId, fmt.Errorf("failed to complete multipart upload: %w", apiError(err)))
+	}
+
+	return out, b.objectURL(ctx, *input.Key), nil
+}
+
+func multipartSettings(partSize *int64, concurrency *int) (int64, int, error) {
+	size := DefaultPartSize
+	if partSize != nil {
+		if *partSize < MinPartSize {
+			return 0, 0, paramError("PartSize", "'PartSize' must be at least %d bytes", MinPartSize)
+		}
+
+		size = *partSize
+	}
+
+	workers := DefaultConcurrency
+	if concurrency != nil {
+		if *concurrency < 1 {
+			return 0, 0, paramError("Concurrency", "'Concurrency' must be at least 1")
+		}
+
+		workers = *concurrency
+	}
+
+	return size, workers, nil
+}
+
+// uploadParts reads body in partSize chunks and uploads them with up to
+// concurrency requests in flight. At most concurrency+1 chunks are held in
+// memory at any time. The returned parts are sorted by part number.
+func (b *Bucket) uploadParts(ctx context.Context, key, uploadID *string, body io.Reader, partSize int64, concurrency int, enc *Encryption) ([]types.CompletedPart, error) {
+	w, ctx := newWorkers(ctx)
+
+	type chunk struct {
+		number int32
+		data   []byte
+	}
+
+	var (
+		mu    sync.Mutex
+		parts []types.CompletedPart
+	)
+
+	chunks := make(chan chunk)
+	buffers := make(chan []byte, concurrency+1)
+	for i := 0; i < cap(buffers); i++ {
+		buffers <- make([]byte, partSize)
+	}
+
+	w.start(concurrency, func() {
+		for c := range chunks {
+			part, err := b.uploadPart(ctx, key, uploadID, c.number, bytes.NewReader(c.data), int64(len(c.data)), enc)
+
+			buffers <- c.data[:cap(c.data)]
+
+			if err != nil {
+				w.fail(err)
+				continue
+			}
+
+			mu.Lock()
+			parts = append(parts, part)
+			mu.Unlock()
+		}
+	})
+
+	var number int32
+	for {
+		var buf []byte
+		select {
+		case buf = <-buffers:
+		case <-ctx.Done():
+		}
+		if buf == nil {
+			break
+		}
+
+		n, err := io.ReadFull(body, buf)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			w.fail(fmt.Errorf("failed to read body: %w", err))
+			break
+		}
+
+		// S3 needs at least one part, even when the body is empty.
+		if n == 0 && number > 0 {
+			break
+		}
+
+		number++
+		if number > MaxUploadParts {
+			w.fail(fmt.Errorf("body exceeds %d parts of %d bytes", MaxUploadParts, partSize))
+			break
+		}
+
+		select {
+		case chunks <- chunk{number: number, data: buf[:n]}:
+		case <-ctx.Done():
+		}
+
+		if n < len(buf) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	close(chunks)
+
+	if err := w.wait(); err != nil {
+		return nil, err
+	}
+
+	sortParts(parts)
+
+	return parts, nil
+}
+
+func (b *Bucket) uploadPart(ctx context.Context, key, uploadID *string, number int32, body io.ReadSeeker, size int64, enc *Encryption) (types.CompletedPart, error) {
+	in := &s3.UploadPartInput{
+		Bucket:        b.Name,
+		Key:           key,
+		UploadId:      uploadID,
+		PartNumber:    aws.Int32(number),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+	}
+	enc.applyUploadPart(in)
+
+	out, err := b.Client.UploadPart(ctx, in)
+	if err != nil {
+		return types.CompletedPart{}, fmt.Errorf("failed to upload part %d: %w", number, apiError(err))
+	}
+
+	return types.CompletedPart{
+		ETag:       out.ETag,
+		PartNumber: aws.Int32(number),
+	}, nil
+}
+
+func sortParts(parts []types.CompletedPart) {
+	sort.Slice(parts, func(i, j int) bool {
+		return *parts[i].PartNumber < *parts[j].PartNumber
+	})
+}
+
+// abortMultipartUpload aborts the upload so its parts stop accruing storage
+// and returns cause, joined with the abort error if that fails too. The abort
+// is still sent when ctx has been canceled, since that is often the cause.
+func (b *Bucket) abortMultipartUpload(ctx context.Context, key, uploadID *string, cause error) error {
+	_, err := b.Client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+		Bucket:   b.Name,
+		Key:      key,
+		UploadId: uploadID,
+	})
+	if err != nil {
+		return errors.Join(cause, fmt.Errorf("failed to abort multipart upload: %w", apiError(err)))
+	}
+
+	return cause
+}
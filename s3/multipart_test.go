@@ -0,0 +1,195 @@
+package s3_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/itispx/goaws/s3"
+)
+
+func TestBucket_UploadMultipart(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	data := bytes.Repeat([]byte("goaws"), int(s3.MinPartSize)/2)
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	key := "multipart-test"
+	concurrency := 2
+
+	_, url, err := bct.UploadMultipart(&s3.BucketUploadMultipartInput{
+		Body:        bytes.NewReader(data),
+		Key:         &key,
+		Concurrency: &concurrency,
+	})
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	assertURL := objectURL(bucket, region, key)
+
+	if url != assertURL {
+		t.Error("incorrect path")
+	}
+
+	svc, err := getSVC(region)
+	if err != nil {
+		t.Fatalf("get object fail: %s", err.Error())
+	}
+
+	out, err := svc.GetObject(context.TODO(), &awss3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		t.Fatalf("get object fail: %s", err.Error())
+	}
+	defer out.Body.Close()
+
+	got, err := io.ReadAll(out.Body)
+	if err != nil {
+		t.Fatalf("get object fail: %s", err.Error())
+	}
+
+	if !bytes.Equal(got, data) {
+		t.Errorf("expected %d bytes, got %d", len(data), len(got))
+	}
+
+	t.Cleanup(func() {
+		err = deleteObject(bucket, region, key)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func TestBucket_UploadMultipartNilInput(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.UploadMultipart(nil)
+	if err == nil || err.Error() != "nil input" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_UploadMultipartEmptyInput(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.UploadMultipart(&s3.BucketUploadMultipartInput{})
+	if err == nil || err.Error() != "empty input" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_UploadMultipartNilBody(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := "key-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.UploadMultipart(&s3.BucketUploadMultipartInput{
+		Key: &key,
+	})
+	if err == nil || err.Error() != "empty 'Body' param" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_UploadMultipartEmptyKey(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := ""
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.UploadMultipart(&s3.BucketUploadMultipartInput{
+		Body: bytes.NewReader(nil),
+		Key:  &key,
+	})
+	if err == nil || err.Error() != "empty 'Key' param" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_UploadMultipartSmallPartSize(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := "key-name"
+	partSize := s3.MinPartSize - 1
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.UploadMultipart(&s3.BucketUploadMultipartInput{
+		Body:     bytes.NewReader(nil),
+		Key:      &key,
+		PartSize: &partSize,
+	})
+	if err == nil || err.Error() != fmt.Sprintf("'PartSize' must be at least %d bytes", s3.MinPartSize) {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_UploadMultipartZeroConcurrency(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := "key-name"
+	concurrency := 0
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.UploadMultipart(&s3.BucketUploadMultipartInput{
+		Body:        bytes.NewReader(nil),
+		Key:         &key,
+		Concurrency: &concurrency,
+	})
+	if err == nil || err.Error() != "'Concurrency' must be at least 1" {
+		t.Error("invalid error message")
+	}
+}
@@ -0,0 +1,464 @@
+package s3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// NotificationRule sends the listed events for keys matching Prefix and
+// Suffix to one destination: exactly one of QueueARN (SQS), TopicARN (SNS)
+// or FunctionARN (Lambda) must be set. Events may use wildcards such as
+// types.EventS3ObjectCreated.
+type NotificationRule struct {
+	ID          string
+	Events      []types.Event
+	Prefix      string
+	Suffix      string
+	QueueARN    string
+	TopicARN    string
+	FunctionARN string
+}
+
+// NotificationConfiguration is a bucket's notification setup. With
+// EventBridge set, every event is also sent to Amazon EventBridge.
+type NotificationConfiguration struct {
+	Rules       []NotificationRule
+	EventBridge bool
+}
+
+func (r *NotificationRule) validate() error {
+	set := 0
+	for _, v := range []string{r.QueueARN, r.TopicARN, r.FunctionARN} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return paramError("QueueARN", "exactly one of 'QueueARN', 'TopicARN' or 'FunctionARN' must be set")
+	}
+
+	if len(r.Events) == 0 {
+		return emptyParam("Events")
+	}
+	for _, e := range r.Events {
+		if !strings.HasPrefix(string(e), "s3:") {
+			return paramError("Events", "unsupported event '%s'", e)
+		}
+	}
+
+	if len(r.ID) > 255 {
+		return paramError("ID", "'ID' must be at most 255 characters")
+	}
+
+	return nil
+}
+
+func (r *NotificationRule) filter() *types.NotificationConfigurationFilter {
+	var rules []types.FilterRule
+	if r.Prefix != "" {
+		rules = append(rules, types.FilterRule{Name: types.FilterRuleNamePrefix, Value: aws.String(r.Prefix)})
+	}
+	if r.Suffix != "" {
+		rules = append(rules, types.FilterRule{Name: types.FilterRuleNameSuffix, Value: aws.String(r.Suffix)})
+	}
+	if rules == nil {
+		return nil
+	}
+
+	return &types.NotificationConfigurationFilter{
+		Key: &types.S3KeyFilter{FilterRules: rules},
+	}
+}
+
+func notificationFilter(f *types.NotificationConfigurationFilter) (prefix, suffix string) {
+	if f == nil || f.Key == nil {
+		return "", ""
+	}
+
+	for _, r := range f.Key.FilterRules {
+		switch types.FilterRuleName(strings.ToLower(string(r.Name))) {
+		case types.FilterRuleNamePrefix:
+			prefix = aws.ToString(r.Value)
+		case types.FilterRuleNameSuffix:
+			suffix = aws.ToString(r.Value)
+		}
+	}
+
+	return prefix, suffix
+}
+
+func (c *NotificationConfiguration) toSDK() *types.NotificationConfiguration {
+	out := &types.NotificationConfiguration{}
+
+	for _, r := range c.Rules {
+		var id *string
+		if r.ID != "" {
+			id = aws.String(r.ID)
+		}
+
+		switch {
+		case r.QueueARN != "":
+			out.QueueConfigurations = append(out.QueueConfigurations, types.QueueConfiguration{
+				Id:       id,
+				Events:   r.Events,
+				QueueArn: aws.String(r.QueueARN),
+				Filter:   r.filter(),
+			})
+		case r.TopicARN != "":
+			out.TopicConfigurations = append(out.TopicConfigurations, types.TopicConfiguration{
+				Id:       id,
+				Events:   r.Events,
+				TopicArn: aws.String(r.TopicARN),
+				Filter:   r.filter(),
+			})
+		default:
+			out.LambdaFunctionConfigurations = append(out.LambdaFunctionConfigurations, types.LambdaFunctionConfiguration{
+				Id:                id,
+				Events:            r.Events,
+				LambdaFunctionArn: aws.String(r.FunctionARN),
+				Filter:            r.filter(),
+			})
+		}
+	}
+
+	if c.EventBridge {
+		out.EventBridgeConfiguration = &types.EventBridgeConfiguration{}
+	}
+
+	return out
+}
+
+type BucketPutNotificationsInput struct {
+	Rules       *[]NotificationRule
+	EventBridge *bool
+
+	// SkipDestinationValidation stops S3 from sending a test event to each
+	// destination before saving the configuration.
+	SkipDestinationValidation *bool
+}
+
+// PutNotifications replaces the bucket's notification configuration.
+func (b *Bucket) PutNotifications(input *BucketPutNotificationsInput) (*s3.PutBucketNotificationConfigurationOutput, error) {
+	return b.PutNotificationsContext(context.Background(), input)
+}
+
+func (b *Bucket) PutNotificationsContext(ctx context.Context, input *BucketPutNotificationsInput) (*s3.PutBucketNotificationConfigurationOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if *input == (BucketPutNotificationsInput{}) {
+		return nil, emptyInput()
+	}
+	if (input.Rules == nil || len(*input.Rules) == 0) && !aws.ToBool(input.EventBridge) {
+		return nil, emptyParam("Rules")
+	}
+
+	config := &NotificationConfiguration{
+		EventBridge: aws.ToBool(input.EventBridge),
+	}
+
+	if input.Rules != nil {
+		ids := map[string]bool{}
+		for i, r := range *input.Rules {
+			if err := r.validate(); err != nil {
+				return nil, fmt.Errorf("invalid notification rule %d: %w", i, err)
+			}
+			if r.ID != "" && ids[r.ID] {
+				return nil, paramError("ID", "duplicate notification rule ID '%s'", r.ID)
+			}
+			ids[r.ID] = true
+		}
+
+		config.Rules = *input.Rules
+	}
+
+	return b.putNotifications(ctx, config.toSDK(), input.SkipDestinationValidation)
+}
+
+// DeleteNotifications removes every notification rule and turns off
+// EventBridge delivery.
+func (b *Bucket) DeleteNotifications() (*s3.PutBucketNotificationConfigurationOutput, error) {
+	return b.DeleteNotificationsContext(context.Background())
+}
+
+func (b *Bucket) DeleteNotificationsContext(ctx context.Context) (*s3.PutBucketNotificationConfigurationOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+
+	return b.putNotifications(ctx, &types.NotificationConfiguration{}, nil)
+}
+
+func (b *Bucket) putNotifications(ctx context.Context, config *types.NotificationConfiguration, skipValidation *bool) (*s3.PutBucketNotificationConfigurationOutput, error) {
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.PutBucketNotificationConfiguration(ctx, &s3.PutBucketNotificationConfigurationInput{
+		Bucket:                    b.Name,
+		NotificationConfiguration: config,
+		SkipDestinationValidation: skipValidation,
+	}, withoutAccelerate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put bucket notification configuration: %w", apiError(err))
+	}
+
+	return out, nil
+}
+
+// GetNotifications returns the bucket's notification configuration. A
+// bucket without notifications has no rules.
+func (b *Bucket) GetNotifications() (*NotificationConfiguration, error) {
+	return b.GetNotificationsContext(context.Background())
+}
+
+func (b *Bucket) GetNotificationsContext(ctx context.Context) (*NotificationConfiguration, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.GetBucketNotificationConfiguration(ctx, &s3.GetBucketNotificationConfigurationInput{
+		Bucket: b.Name,
+	}, withoutAccelerate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bucket notification configuration: %w", apiError(err))
+	}
+
+	config := &NotificationConfiguration{
+		EventBridge: out.EventBridgeConfiguration != nil,
+	}
+
+	for _, c := range out.QueueConfigurations {
+		prefix, suffix := notificationFilter(c.Filter)
+		config.Rules = append(config.Rules, NotificationRule{
+			ID:       aws.ToString(c.Id),
+			Events:   c.Events,
+			Prefix:   prefix,
+			Suffix:   suffix,
+			QueueARN: aws.ToString(c.QueueArn),
+		})
+	}
+	for _, c := range out.TopicConfigurations {
+		prefix, suffix := notificationFilter(c.Filter)
+		config.Rules = append(config.Rules, NotificationRule{
+			ID:       aws.ToString(c.Id),
+			Events:   c.Events,
+			Prefix:   prefix,
+			Suffix:   suffix,
+			TopicARN: aws.ToString(c.TopicArn),
+		})
+	}
+	for _, c := range out.LambdaFunctionConfigurations {
+		prefix, suffix := notificationFilter(c.Filter)
+		config.Rules = append(config.Rules, NotificationRule{
+			ID:          aws.ToString(c.Id),
+			Events:      c.Events,
+			Prefix:      prefix,
+			Suffix:      suffix,
+			FunctionARN: aws.ToString(c.LambdaFunctionArn),
+		})
+	}
+
+	return config, nil
+}
+
+// NotificationEvent is the message S3 sends to SQS, SNS and Lambda
+// destinations. S3 test events, sent when a configuration is saved, have no
+// records and set TestEvent, which is also set when any message of a batch
+// was one.
+type NotificationEvent struct {
+	Records   []EventRecord `json:"Records"`
+	TestEvent bool          `json:"-"`
+}
+
+type EventRecord struct {
+	EventVersion string    `json:"eventVersion"`
+	EventSource  string    `json:"eventSource"`
+	AWSRegion    string    `json:"awsRegion"`
+	EventTime    time.Time `json:"eventTime"`
+
+	// EventName is the event without the "s3:" prefix, such as
+	// "ObjectCreated:Put". Use Event to compare it with types.Event values.
+	EventName         string            `json:"eventName"`
+	UserIdentity      EventIdentity     `json:"userIdentity"`
+	RequestParameters EventRequest      `json:"requestParameters"`
+	ResponseElements  map[string]string `json:"responseElements"`
+	S3                EventEntity       `json:"s3"`
+}
+
+type EventIdentity struct {
+	PrincipalID string `json:"principalId"`
+}
+
+type EventRequest struct {
+	SourceIPAddress string `json:"sourceIPAddress"`
+}
+
+type EventEntity struct {
+	SchemaVersion   string      `json:"s3SchemaVersion"`
+	ConfigurationID string      `json:"configurationId"`
+	Bucket          EventBucket `json:"bucket"`
+	Object          EventObject `json:"object"`
+}
+
+type EventBucket struct {
+	Name          string        `json:"name"`
+	OwnerIdentity EventIdentity `json:"ownerIdentity"`
+	ARN           string        `json:"arn"`
+}
+
+// EventObject describes the object the event is about. DecodeEvent
+// URL-decodes Key; in the raw message it is form-encoded.
+type EventObject struct {
+	Key       string `json:"key"`
+	Size      int64  `json:"size"`
+	ETag      string `json:"eTag"`
+	VersionID string `json:"versionId"`
+	Sequencer string `json:"sequencer"`
+}
+
+// Event returns the record's event name as a types.Event, for example
+// types.EventS3ObjectCreatedPut.
+func (r *EventRecord) Event() types.Event {
+	return types.Event("s3:" + r.EventName)
+}
+
+// DecodeEvent decodes an S3 event notification as received by an SQS queue,
+// SNS topic or Lambda function. Messages delivered through SNS, including to
+// queues subscribed to a topic, are unwrapped first. A Lambda invocation can
+// batch several SNS or SQS messages, whose records are returned together.
+func DecodeEvent(data []byte) (*NotificationEvent, error) {
+	var envelope struct {
+		Type    string `json:"Type"`
+		Message string `json:"Message"`
+		Event   string `json:"Event"`
+		Records []struct {
+			EventSource string `json:"eventSource"`
+			Body        string `json:"body"`
+			Sns         struct {
+				Message string `json:"Message"`
+			} `json:"Sns"`
+		} `json:"Records"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode event: %w", err)
+	}
+
+	if envelope.Event == "s3:TestEvent" {
+		return &NotificationEvent{TestEvent: true}, nil
+	}
+
+	// SNS delivery to SQS or HTTP.
+	if envelope.Type == "Notification" {
+		return DecodeEvent([]byte(envelope.Message))
+	}
+
+	// Lambda invocations by SNS or SQS. SNS spells the field EventSource,
+	// which json matches all the same.
+	if len(envelope.Records) > 0 {
+		switch envelope.Records[0].EventSource {
+		case "aws:sns", "aws:sqs":
+			event := &NotificationEvent{}
+			for i, r := range envelope.Records {
+				message := r.Body
+				if r.EventSource == "aws:sns" {
+					message = r.Sns.Message
+				}
+
+				e, err := DecodeEvent([]byte(message))
+				if err != nil {
+					return nil, fmt.Errorf("failed to decode message %d: %w", i, err)
+				}
+
+				event.Records = append(event.Records, e.Records...)
+				event.TestEvent = event.TestEvent || e.TestEvent
+			}
+
+			return event, nil
+		}
+	}
+
+	event := &NotificationEvent{}
+	if err := json.Unmarshal(data, event); err != nil {
+		return nil, fmt.Errorf("failed to decode event: %w", err)
+	}
+
+	for i := range event.Records {
+		o := &event.Records[i].S3.Object
+
+		key, err := url.QueryUnescape(o.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key '%s' in record %d: %w", o.Key, i, err)
+		}
+
+		o.Key = key
+	}
+
+	return event, nil
+}
+
+// EventBridgeEvent is an S3 event as delivered by Amazon EventBridge. Its
+// object keys are not URL-encoded.
+type EventBridgeEvent struct {
+	Version    string            `json:"version"`
+	ID         string            `json:"id"`
+	DetailType string            `json:"detail-type"`
+	Source     string            `json:"source"`
+	Account    string            `json:"account"`
+	Time       time.Time         `json:"time"`
+	Region     string            `json:"region"`
+	Resources  []string          `json:"resources"`
+	Detail     EventBridgeDetail `json:"detail"`
+}
+
+type EventBridgeDetail struct {
+	Version string `json:"version"`
+	Bucket  struct {
+		Name string `json:"name"`
+	} `json:"bucket"`
+	Object struct {
+		Key       string `json:"key"`
+		Size      int64  `json:"size"`
+		ETag      string `json:"etag"`
+		VersionID string `json:"version-id"`
+		Sequencer string `json:"sequencer"`
+	} `json:"object"`
+	RequestID       string `json:"request-id"`
+	Requester       string `json:"requester"`
+	SourceIPAddress string `json:"source-ip-address"`
+	Reason          string `json:"reason"`
+	DeletionType    string `json:"deletion-type"`
+}
+
+func DecodeEventBridgeEvent(data []byte) (*EventBridgeEvent, error) {
+	event := &EventBridgeEvent{}
+	if err := json.Unmarshal(data, event); err != nil {
+		return nil, fmt.Errorf("failed to decode event: %w", err)
+	}
+	if event.Source != "aws.s3" {
+		return nil, fmt.Errorf("unexpected event source '%s'", event.Source)
+	}
+
+	return event, nil
+}
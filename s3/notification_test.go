@@ -0,0 +1,341 @@
+package s3_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/itispx/goaws/s3"
+)
+
+func TestBucket_Notifications(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	config, err := bct.GetNotifications()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(config.Rules) != 0 || config.EventBridge {
+		t.Errorf("expected no notifications, got %+v", config)
+	}
+
+	_, err = bct.PutNotifications(&s3.BucketPutNotificationsInput{
+		EventBridge: aws.Bool(true),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	config, err = bct.GetNotifications()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !config.EventBridge {
+		t.Error("expected EventBridge to be enabled")
+	}
+
+	_, err = bct.DeleteNotifications()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	t.Cleanup(func() {
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func TestBucket_PutNotificationsInvalidRule(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	rules := []s3.NotificationRule{
+		{
+			Events:   []types.Event{types.EventS3ObjectCreated},
+			QueueARN: "arn:aws:sqs:us-east-1:123456789012:queue",
+			TopicARN: "arn:aws:sns:us-east-1:123456789012:topic",
+		},
+	}
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.PutNotifications(&s3.BucketPutNotificationsInput{
+		Rules: &rules,
+	})
+	if err == nil || err.Error() != "invalid notification rule 0: exactly one of 'QueueARN', 'TopicARN' or 'FunctionARN' must be set" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_PutNotificationsEmptyRules(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	rules := []s3.NotificationRule{}
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.PutNotifications(&s3.BucketPutNotificationsInput{
+		Rules: &rules,
+	})
+	if err == nil || err.Error() != "empty 'Rules' param" {
+		t.Error("invalid error message")
+	}
+}
+
+const eventMessage = `{
+	"Records": [
+		{
+			"eventVersion": "2.1",
+			"eventSource": "aws:s3",
+			"awsRegion": "us-west-2",
+			"eventTime": "1970-01-01T00:00:00.000Z",
+			"eventName": "ObjectCreated:Put",
+			"userIdentity": {"principalId": "AIDAJDPLRKLG7UEXAMPLE"},
+			"requestParameters": {"sourceIPAddress": "127.0.0.1"},
+			"responseElements": {
+				"x-amz-request-id": "C3D13FE58DE4C810",
+				"x-amz-id-2": "FMyUVURIY8/IgAtTv8xRjskZQpcIZ9KG4V5Wp6S7S/JRWeUWerMUE5JgHvANOjpD"
+			},
+			"s3": {
+				"s3SchemaVersion": "1.0",
+				"configurationId": "testConfigRule",
+				"bucket": {
+					"name": "amzn-s3-demo-bucket",
+					"ownerIdentity": {"principalId": "A3NL1KOZZKExample"},
+					"arn": "arn:aws:s3:::amzn-s3-demo-bucket"
+				},
+				"object": {
+					"key": "photos/my+cat%28old%29.jpg",
+					"size": 1024,
+					"eTag": "d41d8cd98f00b204e9800998ecf8427e",
+					"versionId": "096fKKXTRTtl3on89fVO.nfljtsv6qko",
+					"sequencer": "0055AED6DCD90281E5"
+				}
+			}
+		}
+	]
+}`
+
+func TestDecodeEvent(t *testing.T) {
+	t.Parallel()
+
+	wrapped, err := json.Marshal(map[string]string{
+		"Type":    "Notification",
+		"Message": eventMessage,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "Direct",
+			data: []byte(eventMessage),
+		},
+		{
+			name: "SNS",
+			data: wrapped,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			event, err := s3.DecodeEvent(tt.data)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if len(event.Records) != 1 {
+				t.Fatalf("expected 1 record, got %d", len(event.Records))
+			}
+
+			r := event.Records[0]
+
+			if r.Event() != types.EventS3ObjectCreatedPut {
+				t.Errorf("expected '%s', got '%s'", types.EventS3ObjectCreatedPut, r.Event())
+			}
+			if r.S3.Bucket.Name != "amzn-s3-demo-bucket" {
+				t.Errorf("expected 'amzn-s3-demo-bucket', got '%s'", r.S3.Bucket.Name)
+			}
+			if r.S3.Object.Key != "photos/my cat(old).jpg" {
+				t.Errorf("expected 'photos/my cat(old).jpg', got '%s'", r.S3.Object.Key)
+			}
+			if r.S3.Object.Size != 1024 {
+				t.Errorf("expected 1024, got %d", r.S3.Object.Size)
+			}
+		})
+	}
+}
+
+const testEventMessage = `{"Service":"Amazon S3","Event":"s3:TestEvent","Time":"2014-10-13T15:57:02.089Z","Bucket":"amzn-s3-demo-bucket","RequestId":"5582815E1AEA5ADF","HostId":"8cLeGAmw098X5cv4Zkwcmo8vvZa3eH3eKxsPzbB9wrR+YstdA6Knx4Ip8EXAMPLE"}`
+
+// snsMessage wraps message the way SNS delivers it to SQS and HTTP.
+func snsMessage(t *testing.T, message string) string {
+	data, err := json.Marshal(map[string]string{
+		"Type":    "Notification",
+		"Message": message,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return string(data)
+}
+
+// lambdaEvent batches bodies the way SNS or SQS invoke a Lambda function.
+func lambdaEvent(t *testing.T, source string, bodies ...string) []byte {
+	records := []map[string]any{}
+	for _, b := range bodies {
+		if source == "aws:sns" {
+			records = append(records, map[string]any{"EventSource": source, "Sns": map[string]string{"Message": b}})
+		} else {
+			records = append(records, map[string]any{"eventSource": source, "body": b})
+		}
+	}
+
+	data, err := json.Marshal(map[string]any{"Records": records})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return data
+}
+
+func TestDecodeEvent_TestEvent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"Direct", []byte(testEventMessage)},
+		{"SNS", []byte(snsMessage(t, testEventMessage))},
+		{"LambdaSNS", lambdaEvent(t, "aws:sns", testEventMessage)},
+		{"LambdaSQS", lambdaEvent(t, "aws:sqs", snsMessage(t, testEventMessage))},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			event, err := s3.DecodeEvent(tt.data)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if !event.TestEvent || len(event.Records) != 0 {
+				t.Errorf("expected a test event, got %+v", event)
+			}
+		})
+	}
+}
+
+func TestDecodeEvent_Batch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		data      []byte
+		testEvent bool
+	}{
+		{"LambdaSNS", lambdaEvent(t, "aws:sns", eventMessage, eventMessage), false},
+		{"LambdaSQS", lambdaEvent(t, "aws:sqs", snsMessage(t, eventMessage), eventMessage), false},
+		{"LambdaSQSWithTestEvent", lambdaEvent(t, "aws:sqs", testEventMessage, eventMessage, snsMessage(t, eventMessage)), true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			event, err := s3.DecodeEvent(tt.data)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if len(event.Records) != 2 {
+				t.Fatalf("expected 2 records, got %d", len(event.Records))
+			}
+			if event.TestEvent != tt.testEvent {
+				t.Errorf("expected TestEvent %t, got %t", tt.testEvent, event.TestEvent)
+			}
+
+			for _, r := range event.Records {
+				if r.S3.Object.Key != "photos/my cat(old).jpg" {
+					t.Errorf("expected 'photos/my cat(old).jpg', got '%s'", r.S3.Object.Key)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeEvent_InvalidBatch(t *testing.T) {
+	t.Parallel()
+
+	_, err := s3.DecodeEvent(lambdaEvent(t, "aws:sqs", eventMessage, "{"))
+	if err == nil || err.Error() != "failed to decode message 1: failed to decode event: unexpected end of JSON input" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestDecodeEventBridgeEvent(t *testing.T) {
+	t.Parallel()
+
+	event, err := s3.DecodeEventBridgeEvent([]byte(`{
+		"version": "0",
+		"id": "17793124-05d4-b198-2fde-7ededc63b103",
+		"detail-type": "Object Created",
+		"source": "aws.s3",
+		"account": "111122223333",
+		"time": "2021-11-12T00:00:00Z",
+		"region": "ca-central-1",
+		"resources": ["arn:aws:s3:::amzn-s3-demo-bucket1"],
+		"detail": {
+			"version": "0",
+			"bucket": {"name": "amzn-s3-demo-bucket1"},
+			"object": {"key": "example key", "size": 5, "etag": "b1946ac92492d2347c6235b4d2611184", "sequencer": "617f08299329d189"},
+			"request-id": "N4N7GDK58NMKJ12R",
+			"requester": "123456789012",
+			"source-ip-address": "1.2.3.4",
+			"reason": "PutObject"
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if event.DetailType != "Object Created" || event.Detail.Object.Key != "example key" || event.Detail.Reason != "PutObject" {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
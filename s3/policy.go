@@ -0,0 +1,370 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+const PolicyVersion = "2012-10-17"
+
+type Effect string
+
+const (
+	EffectAllow Effect = "Allow"
+	EffectDeny  Effect = "Deny"
+)
+
+// Policy is an IAM policy document as used by bucket policies.
+type Policy struct {
+	Version   string      `json:"Version,omitempty"`
+	Id        string      `json:"Id,omitempty"`
+	Statement []Statement `json:"Statement"`
+}
+
+type Statement struct {
+	Sid          string     `json:"Sid,omitempty"`
+	Effect       Effect     `json:"Effect"`
+	Principal    *Principal `json:"Principal,omitempty"`
+	NotPrincipal *Principal `json:"NotPrincipal,omitempty"`
+	Action       StringList `json:"Action,omitempty"`
+	NotAction    StringList `json:"NotAction,omitempty"`
+	Resource     StringList `json:"Resource,omitempty"`
+	NotResource  StringList `json:"NotResource,omitempty"`
+	Condition    Condition  `json:"Condition,omitempty"`
+}
+
+// Condition maps a condition operator such as "StringLike" to the keys it
+// tests and the values they are compared with.
+type Condition map[string]map[string]StringList
+
+// Principal is either the "*" wildcard or a set of principals by type.
+type Principal struct {
+	Wildcard      bool       `json:"-"`
+	AWS           StringList `json:"AWS,omitempty"`
+	Service       StringList `json:"Service,omitempty"`
+	Federated     StringList `json:"Federated,omitempty"`
+	CanonicalUser StringList `json:"CanonicalUser,omitempty"`
+}
+
+// StringList is a policy value that may be written either as a single string
+// or as an array of strings. One element is encoded as a plain string.
+// Booleans and numbers, which appear in conditions, are decoded as strings.
+type StringList []string
+
+func NewPolicy(statements ...Statement) *Policy {
+	return &Policy{
+		Version:   PolicyVersion,
+		Statement: statements,
+	}
+}
+
+func (p *Policy) UnmarshalJSON(data []byte) error {
+	type policy Policy
+
+	var raw struct {
+		policy
+		Statement json.RawMessage `json:"Statement"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*p = Policy(raw.policy)
+	p.Statement = nil
+
+	// A policy with a single statement may omit the array.
+	statement := bytes.TrimSpace(raw.Statement)
+	switch {
+	case len(statement) == 0 || bytes.Equal(statement, []byte("null")):
+		return nil
+	case statement[0] == '{':
+		var s Statement
+		if err := json.Unmarshal(statement, &s); err != nil {
+			return err
+		}
+		p.Statement = []Statement{s}
+		return nil
+	default:
+		return json.Unmarshal(statement, &p.Statement)
+	}
+}
+
+func (p Principal) MarshalJSON() ([]byte, error) {
+	if p.Wildcard {
+		return []byte(`"*"`), nil
+	}
+
+	type principal Principal
+
+	return json.Marshal(principal(p))
+}
+
+func (p *Principal) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if s != "*" {
+			return fmt.Errorf("invalid principal '%s'", s)
+		}
+
+		*p = Principal{Wildcard: true}
+		return nil
+	}
+
+	type principal Principal
+
+	var v principal
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*p = Principal(v)
+	p.Wildcard = false
+
+	return nil
+}
+
+func (l StringList) MarshalJSON() ([]byte, error) {
+	if len(l) == 1 {
+		return json.Marshal(l[0])
+	}
+
+	return json.Marshal([]string(l))
+}
+
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var values []any
+	if err := json.Unmarshal(data, &values); err != nil {
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		values = []any{v}
+	}
+
+	out := make(StringList, 0, len(values))
+	for _, v := range values {
+		switch v := v.(type) {
+		case string:
+			out = append(out, v)
+		case bool:
+			out = append(out, strconv.FormatBool(v))
+		case float64:
+			out = append(out, strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			return fmt.Errorf("invalid policy value %v", v)
+		}
+	}
+
+	*l = out
+
+	return nil
+}
+
+// Validate checks the parts of the document S3 requires in a bucket policy.
+func (p *Policy) Validate() error {
+	if len(p.Statement) == 0 {
+		return paramError("Statement", "policy has no statements")
+	}
+
+	sids := map[string]bool{}
+
+	for i, s := range p.Statement {
+		name := strconv.Itoa(i)
+		if s.Sid != "" {
+			name = "'" + s.Sid + "'"
+
+			if sids[s.Sid] {
+				return paramError("Sid", "duplicate statement Sid '%s'", s.Sid)
+			}
+			sids[s.Sid] = true
+		}
+
+		switch {
+		case s.Effect != EffectAllow && s.Effect != EffectDeny:
+			return paramError("Effect", "statement %s: invalid effect '%s'", name, s.Effect)
+		case (s.Principal == nil) == (s.NotPrincipal == nil):
+			return paramError("Principal", "statement %s: exactly one of 'Principal' and 'NotPrincipal' is required", name)
+		case (len(s.Action) == 0) == (len(s.NotAction) == 0):
+			return paramError("Action", "statement %s: exactly one of 'Action' and 'NotAction' is required", name)
+		case (len(s.Resource) == 0) == (len(s.NotResource) == 0):
+			return paramError("Resource", "statement %s: exactly one of 'Resource' and 'NotResource' is required", name)
+		}
+	}
+
+	return nil
+}
+
+func bucketARN(bucket string) string {
+	return "arn:aws:s3:::" + bucket
+}
+
+func objectsARN(bucket, prefix string) string {
+	return bucketARN(bucket) + "/" + prefix + "*"
+}
+
+// DenyInsecureTransport rejects every request to the bucket that is not
+// sent over TLS.
+func DenyInsecureTransport(bucket string) Statement {
+	return Statement{
+		Sid:       "DenyInsecureTransport",
+		Effect:    EffectDeny,
+		Principal: &Principal{Wildcard: true},
+		Action:    StringList{"s3:*"},
+		Resource:  StringList{bucketARN(bucket), objectsARN(bucket, "")},
+		Condition: Condition{
+			"Bool": {"aws:SecureTransport": {"false"}},
+		},
+	}
+}
+
+// PublicReadPrefix lets anyone read objects under prefix. The bucket's
+// Public Access Block settings must allow public policies for it to apply.
+func PublicReadPrefix(bucket, prefix string) Statement {
+	return Statement{
+		Effect:    EffectAllow,
+		Principal: &Principal{Wildcard: true},
+		Action:    StringList{"s3:GetObject"},
+		Resource:  StringList{objectsARN(bucket, prefix)},
+	}
+}
+
+// RoleReadWritePrefix lets the role list, read, write and delete objects
+// under prefix.
+func RoleReadWritePrefix(bucket, prefix, roleARN string) []Statement {
+	return []Statement{
+		{
+			Effect:    EffectAllow,
+			Principal: &Principal{AWS: StringList{roleARN}},
+			Action:    StringList{"s3:ListBucket"},
+			Resource:  StringList{bucketARN(bucket)},
+			Condition: Condition{
+				"StringLike": {"s3:prefix": {prefix + "*"}},
+			},
+		},
+		{
+			Effect:    EffectAllow,
+			Principal: &Principal{AWS: StringList{roleARN}},
+			Action:    StringList{"s3:GetObject", "s3:PutObject", "s3:DeleteObject"},
+			Resource:  StringList{objectsARN(bucket, prefix)},
+		},
+	}
+}
+
+type BucketPutPolicyInput struct {
+	Policy *Policy
+}
+
+// PutPolicy replaces the bucket policy.
+func (b *Bucket) PutPolicy(input *BucketPutPolicyInput) (*s3.PutBucketPolicyOutput, error) {
+	return b.PutPolicyContext(context.Background(), input)
+}
+
+func (b *Bucket) PutPolicyContext(ctx context.Context, input *BucketPutPolicyInput) (*s3.PutBucketPolicyOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if *input == (BucketPutPolicyInput{}) {
+		return nil, emptyInput()
+	}
+	if err := input.Policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	doc, err := json.Marshal(input.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode policy: %w", err)
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	policy := string(doc)
+
+	out, err := b.Client.PutBucketPolicy(ctx, &s3.PutBucketPolicyInput{
+		Bucket: b.Name,
+		Policy: &policy,
+	}, withoutAccelerate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put bucket policy: %w", apiError(err))
+	}
+
+	return out, nil
+}
+
+// GetPolicy returns the bucket policy, or nil when the bucket has none.
+func (b *Bucket) GetPolicy() (*Policy, error) {
+	return b.GetPolicyContext(context.Background())
+}
+
+func (b *Bucket) GetPolicyContext(ctx context.Context) (*Policy, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.GetBucketPolicy(ctx, &s3.GetBucketPolicyInput{
+		Bucket: b.Name,
+	}, withoutAccelerate)
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchBucketPolicy" {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to get bucket policy: %w", apiError(err))
+	}
+
+	policy := &Policy{}
+	if err := json.Unmarshal([]byte(*out.Policy), policy); err != nil {
+		return nil, fmt.Errorf("failed to decode policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// DeletePolicy removes the bucket policy.
+func (b *Bucket) DeletePolicy() (*s3.DeleteBucketPolicyOutput, error) {
+	return b.DeletePolicyContext(context.Background())
+}
+
+func (b *Bucket) DeletePolicyContext(ctx context.Context) (*s3.DeleteBucketPolicyOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.DeleteBucketPolicy(ctx, &s3.DeleteBucketPolicyInput{
+		Bucket: b.Name,
+	}, withoutAccelerate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete bucket policy: %w", apiError(err))
+	}
+
+	return out, nil
+}
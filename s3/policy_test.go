@@ -0,0 +1,205 @@
+package s3_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/itispx/goaws/s3"
+)
+
+func TestBucket_Policy(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	policy, err := bct.GetPolicy()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if policy != nil {
+		t.Error("expected no policy")
+	}
+
+	input := s3.NewPolicy(s3.DenyInsecureTransport(bucket))
+
+	_, err = bct.PutPolicy(&s3.BucketPutPolicyInput{
+		Policy: input,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	policy, err = bct.GetPolicy()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if policy == nil || !reflect.DeepEqual(policy.Statement, input.Statement) {
+		t.Errorf("expected %+v, got %+v", input, policy)
+	}
+
+	_, err = bct.DeletePolicy()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	t.Cleanup(func() {
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func TestPolicy_JSON(t *testing.T) {
+	t.Parallel()
+
+	doc := `{
+		"Version": "2012-10-17",
+		"Statement": {
+			"Effect": "Allow",
+			"Principal": {"AWS": ["arn:aws:iam::123456789012:root", "arn:aws:iam::210987654321:root"]},
+			"Action": "s3:GetObject",
+			"Resource": ["arn:aws:s3:::bucket/*"],
+			"Condition": {
+				"Bool": {"aws:SecureTransport": true},
+				"NumericLessThan": {"s3:max-keys": 10}
+			}
+		}
+	}`
+
+	var policy s3.Policy
+	err := json.Unmarshal([]byte(doc), &policy)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	want := s3.Statement{
+		Effect: s3.EffectAllow,
+		Principal: &s3.Principal{
+			AWS: s3.StringList{"arn:aws:iam::123456789012:root", "arn:aws:iam::210987654321:root"},
+		},
+		Action:   s3.StringList{"s3:GetObject"},
+		Resource: s3.StringList{"arn:aws:s3:::bucket/*"},
+		Condition: s3.Condition{
+			"Bool":            {"aws:SecureTransport": {"true"}},
+			"NumericLessThan": {"s3:max-keys": {"10"}},
+		},
+	}
+
+	if len(policy.Statement) != 1 || !reflect.DeepEqual(policy.Statement[0], want) {
+		t.Fatalf("expected %+v, got %+v", want, policy.Statement)
+	}
+
+	out, err := json.Marshal(policy)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var again s3.Policy
+	err = json.Unmarshal(out, &again)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !reflect.DeepEqual(again, policy) {
+		t.Errorf("round trip changed the policy: %s", out)
+	}
+}
+
+func TestPrincipal_Wildcard(t *testing.T) {
+	t.Parallel()
+
+	out, err := json.Marshal(s3.PublicReadPrefix("bucket", "public/"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	want := `{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::bucket/public/*"}`
+	if string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+
+	var s s3.Statement
+	err = json.Unmarshal(out, &s)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if s.Principal == nil || !s.Principal.Wildcard {
+		t.Error("expected a wildcard principal")
+	}
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	t.Parallel()
+
+	policy := s3.NewPolicy(append(
+		s3.RoleReadWritePrefix("bucket", "data/", "arn:aws:iam::123456789012:role/app"),
+		s3.DenyInsecureTransport("bucket"),
+	)...)
+
+	err := policy.Validate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	policy.Statement[0].Action = nil
+
+	err = policy.Validate()
+	if err == nil || err.Error() != "statement 0: exactly one of 'Action' and 'NotAction' is required" {
+		t.Error("invalid error message")
+	}
+
+	policy = s3.NewPolicy(s3.DenyInsecureTransport("bucket"), s3.DenyInsecureTransport("bucket"))
+
+	err = policy.Validate()
+	if err == nil || err.Error() != "duplicate statement Sid 'DenyInsecureTransport'" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_PutPolicyNilInput(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.PutPolicy(nil)
+	if err == nil || err.Error() != "nil input" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_PutPolicyNoStatements(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.PutPolicy(&s3.BucketPutPolicyInput{
+		Policy: s3.NewPolicy(),
+	})
+	if err == nil || err.Error() != "policy has no statements" {
+		t.Error("invalid error message")
+	}
+}
@@ -0,0 +1,263 @@
+package s3
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// MaxPostObjectSize is the largest file S3 accepts in a POST upload.
+const MaxPostObjectSize int64 = 5 * 1024 * 1024 * 1024
+
+// PresignPostInput describes the uploads a browser form may make. S3 rejects
+// any upload that does not meet every condition set here.
+type PresignPostInput struct {
+	// Key is the object key. Set KeyPrefix instead to let the form choose
+	// any key starting with it; the key field defaults to the prefix
+	// followed by S3's ${filename} variable.
+	Key       *string
+	KeyPrefix *string
+	Duration  *time.Duration
+
+	// MinSize and MaxSize limit the size of the uploaded file in bytes.
+	MinSize *int64
+	MaxSize *int64
+
+	// ContentType is the Content-Type the form has to send.
+	ContentType *string
+
+	// SuccessActionStatus is the status S3 answers a successful upload
+	// with: 200, 201 or 204. S3 answers 204 when it is not set.
+	SuccessActionStatus *int
+
+	// Metadata is sent as x-amz-meta-* fields, which the form has to send
+	// unchanged.
+	Metadata *map[string]string
+
+	Encryption *Encryption
+}
+
+// PresignedPost is what an HTML form needs to upload to S3: a
+// multipart/form-data POST to URL with Fields, followed by the file in a
+// field named "file".
+type PresignedPost struct {
+	URL     string
+	Fields  map[string]string
+	Expires time.Time
+}
+
+// PresignPost signs a POST policy that lets a browser upload straight to the
+// bucket without credentials, within the limits of input.
+func (b *Bucket) PresignPost(input *PresignPostInput) (*PresignedPost, error) {
+	return b.PresignPostContext(context.Background(), input)
+}
+
+// PresignPostContext is like PresignPost but uses ctx while retrieving
+// credentials.
+func (b *Bucket) PresignPostContext(ctx context.Context, input *PresignPostInput) (*PresignedPost, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if *input == (PresignPostInput{}) {
+		return nil, emptyInput()
+	}
+	if input.Key != nil && input.KeyPrefix != nil {
+		return nil, paramError("KeyPrefix", "'Key' and 'KeyPrefix' cannot be combined")
+	}
+	if input.KeyPrefix == nil && aws.ToString(input.Key) == "" {
+		return nil, emptyParam("Key")
+	}
+	if err := validatePresignDuration(input.Duration); err != nil {
+		return nil, err
+	}
+
+	minSize, maxSize := int64(0), MaxPostObjectSize
+	if input.MinSize != nil {
+		if *input.MinSize < 0 {
+			return nil, paramError("MinSize", "'MinSize' must not be negative")
+		}
+		minSize = *input.MinSize
+	}
+	if input.MaxSize != nil {
+		if *input.MaxSize > MaxPostObjectSize {
+			return nil, paramError("MaxSize", "'MaxSize' must be at most %d bytes", MaxPostObjectSize)
+		}
+		maxSize = *input.MaxSize
+	}
+	if minSize > maxSize {
+		return nil, paramError("MaxSize", "'MaxSize' must be at least 'MinSize'")
+	}
+
+	if input.SuccessActionStatus != nil {
+		switch *input.SuccessActionStatus {
+		case 200, 201, 204:
+		default:
+			return nil, paramError("SuccessActionStatus", "'SuccessActionStatus' must be 200, 201 or 204")
+		}
+	}
+
+	if input.Metadata != nil {
+		for k := range *input.Metadata {
+			if k == "" {
+				return nil, paramError("Metadata", "empty key in 'Metadata' param")
+			}
+		}
+	}
+
+	enc, err := b.encryption(input.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	o := b.Client.Options()
+	if o.Credentials == nil {
+		return nil, errors.New("failed to retrieve credentials: no credentials provider")
+	}
+
+	creds, err := o.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve credentials: %w", err)
+	}
+
+	region := o.Region
+	if region == "" {
+		region = aws.ToString(b.Region)
+	}
+
+	now := time.Now().UTC()
+	expires := now.Add(*input.Duration)
+	date := now.Format("20060102")
+
+	fields := map[string]string{
+		"x-amz-algorithm":  "AWS4-HMAC-SHA256",
+		"x-amz-credential": fmt.Sprintf("%s/%s/%s/s3/aws4_request", creds.AccessKeyID, date, region),
+		"x-amz-date":       now.Format("20060102T150405Z"),
+	}
+	if creds.SessionToken != "" {
+		fields["x-amz-security-token"] = creds.SessionToken
+	}
+	if input.ContentType != nil {
+		fields["Content-Type"] = *input.ContentType
+	}
+	if input.SuccessActionStatus != nil {
+		fields["success_action_status"] = strconv.Itoa(*input.SuccessActionStatus)
+	}
+	if input.Metadata != nil {
+		for k, v := range *input.Metadata {
+			fields["x-amz-meta-"+k] = v
+		}
+	}
+	for k, v := range enc.postFields() {
+		fields[k] = v
+	}
+
+	conditions := []any{
+		map[string]string{"bucket": *b.Name},
+		[]any{"content-length-range", minSize, maxSize},
+	}
+	if input.Key != nil {
+		conditions = append(conditions, map[string]string{"key": *input.Key})
+	} else {
+		conditions = append(conditions, []any{"starts-with", "$key", *input.KeyPrefix})
+	}
+
+	names := make([]string, 0, len(fields))
+	for k := range fields {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		conditions = append(conditions, map[string]string{k: fields[k]})
+	}
+
+	policy, err := json.Marshal(struct {
+		Expiration string `json:"expiration"`
+		Conditions []any  `json:"conditions"`
+	}{
+		Expiration: expires.Format("2006-01-02T15:04:05.000Z"),
+		Conditions: conditions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode policy: %w", err)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(policy)
+
+	fields["policy"] = encoded
+	fields["x-amz-signature"] = postSignature(creds.SecretAccessKey, date, region, encoded)
+
+	if input.Key != nil {
+		fields["key"] = *input.Key
+	} else {
+		fields["key"] = *input.KeyPrefix + "${filename}"
+	}
+
+	return &PresignedPost{
+		URL:     b.bucketURL(ctx),
+		Fields:  fields,
+		Expires: expires,
+	}, nil
+}
+
+// postFields returns the form fields that request e.
+func (e *Encryption) postFields() map[string]string {
+	fields := map[string]string{}
+
+	algorithm, keyID, bucketKey := e.serverSide()
+	if algorithm != "" {
+		fields["x-amz-server-side-encryption"] = string(algorithm)
+	}
+	if keyID != nil {
+		fields["x-amz-server-side-encryption-aws-kms-key-id"] = *keyID
+	}
+	if bucketKey != nil {
+		fields["x-amz-server-side-encryption-bucket-key-enabled"] = "true"
+	}
+
+	customerAlgorithm, customerKey, customerKeyMD5 := e.customer()
+	if customerAlgorithm != nil {
+		fields["x-amz-server-side-encryption-customer-algorithm"] = *customerAlgorithm
+		fields["x-amz-server-side-encryption-customer-key"] = *customerKey
+		fields["x-amz-server-side-encryption-customer-key-MD5"] = *customerKeyMD5
+	}
+
+	return fields
+}
+
+// postSignature signs a base64 policy with a SigV4 signing key for S3.
+func postSignature(secret, date, region, policy string) string {
+	key := hmacSHA256([]byte("AWS4"+secret), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, policy))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
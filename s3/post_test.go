@@ -0,0 +1,161 @@
+package s3_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/itispx/goaws/s3"
+)
+
+// postForm uploads data the way a browser submits the form for post.
+func postForm(post *s3.PresignedPost, fields map[string]string, filename string, data []byte) (*http.Response, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	for k, v := range post.Fields {
+		if _, ok := fields[k]; !ok {
+			w.WriteField(k, v)
+		}
+	}
+	for k, v := range fields {
+		w.WriteField(k, v)
+	}
+
+	f, err := w.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, err
+	}
+	f.Write(data)
+	w.Close()
+
+	return http.Post(post.URL, w.FormDataContentType(), &body)
+}
+
+func TestBucket_PresignPost(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	duration := time.Minute
+	status := 201
+
+	post, err := bct.PresignPost(&s3.PresignPostInput{
+		KeyPrefix:           aws.String("uploads/"),
+		Duration:            &duration,
+		MaxSize:             aws.Int64(10),
+		ContentType:         aws.String("text/plain"),
+		SuccessActionStatus: &status,
+		Metadata:            &map[string]string{"owner": "goaws"},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tests := []struct {
+		name   string
+		fields map[string]string
+		data   string
+		status int
+	}{
+		{"Valid", nil, "goaws", http.StatusCreated},
+		{"TooLarge", nil, "goaws goaws", http.StatusBadRequest},
+		{"WrongContentType", map[string]string{"Content-Type": "text/html"}, "goaws", http.StatusForbidden},
+		{"WrongKey", map[string]string{"key": "other/${filename}"}, "goaws", http.StatusForbidden},
+		{"ExtraField", map[string]string{"x-amz-meta-extra": "goaws"}, "goaws", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := postForm(post, tt.fields, tt.name+".txt", []byte(tt.data))
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+
+	out, err := bct.GetObject(&s3.BucketGetObjectInput{
+		Key: aws.String("uploads/Valid.txt"),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	out.Body.Close()
+
+	if aws.ToString(out.ContentType) != "text/plain" || out.Metadata["owner"] != "goaws" {
+		t.Errorf("unexpected object %s %v", aws.ToString(out.ContentType), out.Metadata)
+	}
+
+	t.Cleanup(func() {
+		err = deleteObject(bucket, region, "uploads/Valid.txt")
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func TestBucket_PresignPostInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	duration := time.Minute
+	tooLong := s3.MaxPresignDuration + time.Second
+	status := 302
+
+	tests := []struct {
+		name  string
+		input *s3.PresignPostInput
+		err   string
+	}{
+		{"NilInput", nil, "nil input"},
+		{"EmptyInput", &s3.PresignPostInput{}, "empty input"},
+		{"EmptyKey", &s3.PresignPostInput{Duration: &duration}, "empty 'Key' param"},
+		{"KeyAndPrefix", &s3.PresignPostInput{Key: aws.String("a"), KeyPrefix: aws.String("b"), Duration: &duration}, "'Key' and 'KeyPrefix' cannot be combined"},
+		{"EmptyDuration", &s3.PresignPostInput{Key: aws.String("a")}, "empty 'Duration' param"},
+		{"DurationTooLong", &s3.PresignPostInput{Key: aws.String("a"), Duration: &tooLong}, "'Duration' must be positive and at most 168h0m0s"},
+		{"SizeRange", &s3.PresignPostInput{Key: aws.String("a"), Duration: &duration, MinSize: aws.Int64(10), MaxSize: aws.Int64(5)}, "'MaxSize' must be at least 'MinSize'"},
+		{"Status", &s3.PresignPostInput{Key: aws.String("a"), Duration: &duration, SuccessActionStatus: &status}, "'SuccessActionStatus' must be 200, 201 or 204"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			bct := s3.Bucket{
+				Name: &name,
+			}
+
+			_, err := bct.PresignPost(tt.input)
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("expected '%s', got %v", tt.err, err)
+			}
+		})
+	}
+}
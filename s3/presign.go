@@ -0,0 +1,208 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// MaxPresignDuration is the longest a SigV4 presigned URL stays valid.
+const MaxPresignDuration = 7 * 24 * time.Hour
+
+// PresignedRequest is a request anyone can send until Expires without
+// credentials. It embeds the SDK's result, so URL, Method and SignedHeader
+// are available as before. Header holds the signed headers other than Host,
+// each with its values joined by commas; the request has to send them with
+// those values. HTTP clients set Content-Length on their own.
+type PresignedRequest struct {
+	*v4.PresignedHTTPRequest
+	Header  map[string]string
+	Expires time.Time
+}
+
+func newPresignedRequest(req *v4.PresignedHTTPRequest, expires time.Time) *PresignedRequest {
+	out := &PresignedRequest{
+		PresignedHTTPRequest: req,
+		Expires:              expires,
+	}
+
+	for k, v := range req.SignedHeader {
+		if k == "Host" || len(v) == 0 {
+			continue
+		}
+		if out.Header == nil {
+			out.Header = map[string]string{}
+		}
+		// SigV4 signs a header's values joined by commas, which is also how
+		// HTTP lets them be sent in a single field.
+		out.Header[http.CanonicalHeaderKey(k)] = strings.Join(v, ",")
+	}
+
+	return out
+}
+
+func validatePresignDuration(d *time.Duration) error {
+	if d == nil {
+		return emptyParam("Duration")
+	}
+	if *d <= 0 || *d > MaxPresignDuration {
+		return paramError("Duration", "'Duration' must be positive and at most %s", MaxPresignDuration)
+	}
+
+	return nil
+}
+
+// presignOptions applies the caller's presign options, with d as the expiry.
+func presignOptions(opts *s3.PresignOptions, d time.Duration) func(*s3.PresignOptions) {
+	return func(o *s3.PresignOptions) {
+		if opts != nil {
+			if opts.Presigner != nil {
+				o.Presigner = opts.Presigner
+			}
+			o.ClientOptions = append(o.ClientOptions, opts.ClientOptions...)
+		}
+
+		o.Expires = d
+	}
+}
+
+type PresignHeadInput struct {
+	Key        *string
+	VersionId  *string
+	Duration   *time.Duration
+	Encryption *Encryption
+
+	// PresignOptions can replace the presigner or add client options. Its
+	// Expires is ignored in favor of Duration.
+	*s3.PresignOptions
+
+	// HeadObjectInput sets other request parameters, such as IfMatch.
+	*s3.HeadObjectInput
+}
+
+// PresignHead presigns a HEAD request, which reads an object's metadata
+// without its body.
+func (b *Bucket) PresignHead(input *PresignHeadInput) (*PresignedRequest, error) {
+	return b.PresignHeadContext(context.Background(), input)
+}
+
+func (b *Bucket) PresignHeadContext(ctx context.Context, input *PresignHeadInput) (*PresignedRequest, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if *input == (PresignHeadInput{}) {
+		return nil, emptyInput()
+	}
+	if input.Key == nil || *input.Key == "" {
+		return nil, emptyParam("Key")
+	}
+	if err := validatePresignDuration(input.Duration); err != nil {
+		return nil, err
+	}
+
+	enc, err := b.encryption(input.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	params := &s3.HeadObjectInput{}
+	if input.HeadObjectInput != nil {
+		*params = *input.HeadObjectInput
+	}
+
+	params.Bucket = b.Name
+	params.Key = input.Key
+	if input.VersionId != nil {
+		params.VersionId = input.VersionId
+	}
+	enc.applyHeadObject(params)
+
+	expires := time.Now().Add(*input.Duration)
+
+	out, err := b.presignClient().PresignHeadObject(ctx, params, presignOptions(input.PresignOptions, *input.Duration))
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign request: %w", apiError(err))
+	}
+
+	return newPresignedRequest(out, expires), nil
+}
+
+type PresignDeleteInput struct {
+	Key       *string
+	VersionId *string
+	Duration  *time.Duration
+
+	// PresignOptions can replace the presigner or add client options. Its
+	// Expires is ignored in favor of Duration.
+	*s3.PresignOptions
+
+	// DeleteObjectInput sets other request parameters, such as
+	// BypassGovernanceRetention.
+	*s3.DeleteObjectInput
+}
+
+// PresignDelete presigns a DELETE request for an object or, with VersionId,
+// one of its versions.
+func (b *Bucket) PresignDelete(input *PresignDeleteInput) (*PresignedRequest, error) {
+	return b.PresignDeleteContext(context.Background(), input)
+}
+
+func (b *Bucket) PresignDeleteContext(ctx context.Context, input *PresignDeleteInput) (*PresignedRequest, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if *input == (PresignDeleteInput{}) {
+		return nil, emptyInput()
+	}
+	if input.Key == nil || *input.Key == "" {
+		return nil, emptyParam("Key")
+	}
+	if err := validatePresignDuration(input.Duration); err != nil {
+		return nil, err
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	params := &s3.DeleteObjectInput{}
+	if input.DeleteObjectInput != nil {
+		*params = *input.DeleteObjectInput
+	}
+
+	params.Bucket = b.Name
+	params.Key = input.Key
+	if input.VersionId != nil {
+		params.VersionId = input.VersionId
+	}
+
+	expires := time.Now().Add(*input.Duration)
+
+	out, err := b.presignClient().PresignDeleteObject(ctx, params, presignOptions(input.PresignOptions, *input.Duration))
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign request: %w", apiError(err))
+	}
+
+	return newPresignedRequest(out, expires), nil
+}
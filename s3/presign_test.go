@@ -0,0 +1,284 @@
+package s3_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/itispx/goaws/s3"
+)
+
+// sendPresigned sends req with its signed headers and body.
+func sendPresigned(req *s3.PresignedRequest, header map[string]string, body string) (*http.Response, error) {
+	r, err := http.NewRequest(req.Method, req.URL, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range req.Header {
+		r.Header.Set(k, v)
+	}
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+
+	return http.DefaultClient.Do(r)
+}
+
+func TestBucket_PresignOptions(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	key := "presigned.txt"
+	duration := time.Minute
+
+	put, err := bct.PresignPut(&s3.PresignPutInput{
+		Key:      &key,
+		Duration: &duration,
+		PutObjectInput: &awss3.PutObjectInput{
+			ContentType:   aws.String("text/plain"),
+			ContentLength: aws.Int64(5),
+			Metadata:      map[string]string{"owner": "goaws"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if put.Method != http.MethodPut || put.Header["Content-Type"] != "text/plain" || put.Header["X-Amz-Meta-Owner"] != "goaws" {
+		t.Errorf("unexpected request %+v", put)
+	}
+	if put.Expires.Before(time.Now()) || put.Expires.After(time.Now().Add(duration)) {
+		t.Errorf("unexpected expiry %s", put.Expires)
+	}
+
+	resp, err := sendPresigned(put, map[string]string{"Content-Type": "text/html"}, "goaws")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected a different Content-Type to be rejected, got %d", resp.StatusCode)
+	}
+
+	resp, err = sendPresigned(put, nil, "goaws")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	get, err := bct.PresignGet(&s3.PresignGetInput{
+		Key:      &key,
+		Duration: &duration,
+		GetObjectInput: &awss3.GetObjectInput{
+			ResponseContentDisposition: aws.String(`attachment; filename="goaws.txt"`),
+			ResponseCacheControl:       aws.String("no-store"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	resp, err = sendPresigned(get, nil, "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != "goaws" || resp.Header.Get("Content-Type") != "text/plain" || resp.Header.Get("X-Amz-Meta-Owner") != "goaws" {
+		t.Errorf("unexpected object %q %v", body, resp.Header)
+	}
+	if resp.Header.Get("Content-Disposition") != `attachment; filename="goaws.txt"` || resp.Header.Get("Cache-Control") != "no-store" {
+		t.Errorf("expected response overrides, got %v", resp.Header)
+	}
+
+	head, err := bct.PresignHead(&s3.PresignHeadInput{
+		Key:      &key,
+		Duration: &duration,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	resp, err = sendPresigned(head, nil, "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	resp.Body.Close()
+
+	if head.Method != http.MethodHead || resp.StatusCode != http.StatusOK || resp.ContentLength != 5 {
+		t.Errorf("unexpected HEAD response %d %d", resp.StatusCode, resp.ContentLength)
+	}
+
+	del, err := bct.PresignDelete(&s3.PresignDeleteInput{
+		Key:      &key,
+		Duration: &duration,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	resp, err = sendPresigned(del, nil, "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", resp.StatusCode)
+	}
+
+	resp, err = sendPresigned(head, nil, "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected the object to be deleted, got %d", resp.StatusCode)
+	}
+
+	t.Cleanup(func() {
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+// headerPresigner signs nothing and answers with a fixed set of headers.
+type headerPresigner http.Header
+
+func (p headerPresigner) PresignHTTP(ctx context.Context, credentials aws.Credentials, r *http.Request, payloadHash, service, region string, signingTime time.Time, optFns ...func(*v4.SignerOptions)) (string, http.Header, error) {
+	return r.URL.String(), http.Header(p), nil
+}
+
+func TestBucket_PresignHeaderValues(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	region := "us-east-1"
+	key := "key-name"
+	duration := time.Minute
+
+	bct := s3.Bucket{
+		Name:   &name,
+		Region: &region,
+	}
+
+	req, err := bct.PresignGet(&s3.PresignGetInput{
+		Key:      &key,
+		Duration: &duration,
+		PresignOptions: &awss3.PresignOptions{
+			Presigner: headerPresigner{
+				"Host":         {"example.com"},
+				"X-Amz-Meta-A": {"1", "2"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(req.Header) != 1 || req.Header["X-Amz-Meta-A"] != "1,2" {
+		t.Errorf("unexpected header %v", req.Header)
+	}
+}
+
+func TestBucket_PresignInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := "key-name"
+	duration := time.Minute
+	negative := -time.Minute
+	tooLong := s3.MaxPresignDuration + time.Second
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		err  string
+	}{
+		{"GetNegativeDuration", func() error {
+			_, err := bct.PresignGet(&s3.PresignGetInput{Key: &key, Duration: &negative})
+			return err
+		}, "'Duration' must be positive and at most 168h0m0s"},
+		{"PutTooLong", func() error {
+			_, err := bct.PresignPut(&s3.PresignPutInput{Key: &key, Duration: &tooLong})
+			return err
+		}, "'Duration' must be positive and at most 168h0m0s"},
+		{"HeadNilInput", func() error {
+			_, err := bct.PresignHead(nil)
+			return err
+		}, "nil input"},
+		{"HeadEmptyInput", func() error {
+			_, err := bct.PresignHead(&s3.PresignHeadInput{})
+			return err
+		}, "empty input"},
+		{"HeadEmptyKey", func() error {
+			_, err := bct.PresignHead(&s3.PresignHeadInput{Duration: &duration})
+			return err
+		}, "empty 'Key' param"},
+		{"HeadEmptyDuration", func() error {
+			_, err := bct.PresignHead(&s3.PresignHeadInput{Key: &key})
+			return err
+		}, "empty 'Duration' param"},
+		{"DeleteNilInput", func() error {
+			_, err := bct.PresignDelete(nil)
+			return err
+		}, "nil input"},
+		{"DeleteEmptyInput", func() error {
+			_, err := bct.PresignDelete(&s3.PresignDeleteInput{})
+			return err
+		}, "empty input"},
+		{"DeleteEmptyKey", func() error {
+			_, err := bct.PresignDelete(&s3.PresignDeleteInput{Duration: &duration})
+			return err
+		}, "empty 'Key' param"},
+		{"DeleteTooLong", func() error {
+			_, err := bct.PresignDelete(&s3.PresignDeleteInput{Key: &key, Duration: &tooLong})
+			return err
+		}, "'Duration' must be positive and at most 168h0m0s"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.call()
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("expected '%s', got %v", tt.err, err)
+			}
+		})
+	}
+}
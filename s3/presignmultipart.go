@@ -0,0 +1,486 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type BucketStartMultipartUploadInput struct {
+	Key        *string
+	Encryption *Encryption
+	*s3.CreateMultipartUploadInput
+}
+
+// StartMultipartUpload creates a multipart upload that a client such as a
+// browser uploads straight to S3:
+//
+//  1. The server calls StartMultipartUpload and PresignUploadParts and hands
+//     the URLs to the client.
+//  2. The client PUTs each part to its URL and reports the ETag header of
+//     each response back.
+//  3. The server calls CompleteMultipartUpload with the reported ETags, or
+//     AbortMultipartUpload to give up.
+//
+// AbortStaleUploads cleans up uploads that clients never finished.
+func (b *Bucket) StartMultipartUpload(input *BucketStartMultipartUploadInput) (*s3.CreateMultipartUploadOutput, error) {
+	return b.StartMultipartUploadContext(context.Background(), input)
+}
+
+func (b *Bucket) StartMultipartUploadContext(ctx context.Context, input *BucketStartMultipartUploadInput) (*s3.CreateMultipartUploadOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if *input == (BucketStartMultipartUploadInput{}) {
+		return nil, emptyInput()
+	}
+	if input.Key == nil || *input.Key == "" {
+		return nil, emptyParam("Key")
+	}
+
+	enc, err := b.encryption(input.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if input.CreateMultipartUploadInput == nil {
+		input.CreateMultipartUploadInput = &s3.CreateMultipartUploadInput{}
+	}
+
+	input.CreateMultipartUploadInput.Key = input.Key
+	input.CreateMultipartUploadInput.Bucket = b.Name
+
+	enc.applyCreateMultipartUpload(input.CreateMultipartUploadInput)
+
+	out, err := b.Client.CreateMultipartUpload(ctx, input.CreateMultipartUploadInput)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create multipart upload: %w", apiError(err))
+	}
+
+	return out, nil
+}
+
+type PresignUploadPartsInput struct {
+	Key      *string
+	UploadId *string
+	Duration *time.Duration
+
+	// Size is the size of the whole object. It is split into parts of
+	// PartSize bytes, the last one holding the rest, and each URL only
+	// accepts a body of its part's size.
+	Size     *int64
+	PartSize *int64
+
+	// PartNumbers limits the URLs to these parts, for example to retry
+	// failed ones. All parts are presigned when it is nil.
+	PartNumbers *[]int32
+
+	// Encryption must match the upload's when it uses SSE-C. The client
+	// has to send the key headers listed in each part's Header.
+	Encryption *Encryption
+}
+
+type PresignedPart struct {
+	PartNumber int32
+	Size       int64
+	*PresignedRequest
+}
+
+// PresignUploadParts presigns an UploadPart request for each part of an
+// upload started with StartMultipartUpload.
+func (b *Bucket) PresignUploadParts(input *PresignUploadPartsInput) ([]PresignedPart, error) {
+	return b.PresignUploadPartsContext(context.Background(), input)
+}
+
+func (b *Bucket) PresignUploadPartsContext(ctx context.Context, input *PresignUploadPartsInput) ([]PresignedPart, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if *input == (PresignUploadPartsInput{}) {
+		return nil, emptyInput()
+	}
+	if input.Key == nil || *input.Key == "" {
+		return nil, emptyParam("Key")
+	}
+	if input.UploadId == nil || *input.UploadId == "" {
+		return nil, emptyParam("UploadId")
+	}
+	if err := validatePresignDuration(input.Duration); err != nil {
+		return nil, err
+	}
+	if input.Size == nil {
+		return nil, emptyParam("Size")
+	}
+	if *input.Size < 1 {
+		return nil, paramError("Size", "'Size' must be at least 1 byte")
+	}
+
+	partSize := DefaultPartSize
+	if input.PartSize != nil {
+		if *input.PartSize < MinPartSize || *input.PartSize > MaxPartSize {
+			return nil, paramError("PartSize", "'PartSize' must be between %d and %d bytes", MinPartSize, MaxPartSize)
+		}
+		partSize = *input.PartSize
+	}
+
+	count := (*input.Size + partSize - 1) / partSize
+	if count > MaxUploadParts {
+		return nil, paramError("PartSize", "'PartSize' is too small to upload %d bytes in at most %d parts", *input.Size, MaxUploadParts)
+	}
+
+	numbers := make([]int32, 0, count)
+	if input.PartNumbers != nil {
+		seen := map[int32]bool{}
+		for _, n := range *input.PartNumbers {
+			if n < 1 || int64(n) > count {
+				return nil, paramError("PartNumbers", "part number %d must be between 1 and %d", n, count)
+			}
+			if seen[n] {
+				return nil, paramError("PartNumbers", "duplicate part number %d in 'PartNumbers' param", n)
+			}
+			seen[n] = true
+			numbers = append(numbers, n)
+		}
+	} else {
+		for n := int32(1); int64(n) <= count; n++ {
+			numbers = append(numbers, n)
+		}
+	}
+
+	enc, err := b.encryption(input.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	presignClient := b.presignClient()
+	expires := time.Now().Add(*input.Duration)
+
+	parts := make([]PresignedPart, 0, len(numbers))
+	for _, n := range numbers {
+		size := min(partSize, *input.Size-int64(n-1)*partSize)
+
+		params := &s3.UploadPartInput{
+			Bucket:        b.Name,
+			Key:           input.Key,
+			UploadId:      input.UploadId,
+			PartNumber:    aws.Int32(n),
+			ContentLength: aws.Int64(size),
+		}
+		enc.applyUploadPart(params)
+
+		req, err := presignClient.PresignUploadPart(ctx, params, s3.WithPresignExpires(*input.Duration))
+		if err != nil {
+			return nil, fmt.Errorf("failed to presign part %d: %w", n, apiError(err))
+		}
+
+		parts = append(parts, PresignedPart{
+			PartNumber:       n,
+			Size:             size,
+			PresignedRequest: newPresignedRequest(req, expires),
+		})
+	}
+
+	return parts, nil
+}
+
+type BucketCompleteMultipartUploadInput struct {
+	Key      *string
+	UploadId *string
+
+	// Parts are the part numbers and ETags the client reported. ETags may
+	// be given with or without quotes.
+	Parts *[]types.CompletedPart
+
+	// Size, when set, is checked against the total size of the parts.
+	Size *int64
+
+	Encryption *Encryption
+}
+
+// CompleteMultipartUpload checks the reported parts against those S3 has
+// stored, then completes the upload. Nothing is aborted when a check fails,
+// so the client can upload the missing parts and try again.
+func (b *Bucket) CompleteMultipartUpload(input *BucketCompleteMultipartUploadInput) (*s3.CompleteMultipartUploadOutput, string, error) {
+	return b.CompleteMultipartUploadContext(context.Background(), input)
+}
+
+func (b *Bucket) CompleteMultipartUploadContext(ctx context.Context, input *BucketCompleteMultipartUploadInput) (*s3.CompleteMultipartUploadOutput, string, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, "", emptyParam("Name")
+	}
+	if input == nil {
+		return nil, "", nilInput()
+	}
+	if *input == (BucketCompleteMultipartUploadInput{}) {
+		return nil, "", emptyInput()
+	}
+	if input.Key == nil || *input.Key == "" {
+		return nil, "", emptyParam("Key")
+	}
+	if input.UploadId == nil || *input.UploadId == "" {
+		return nil, "", emptyParam("UploadId")
+	}
+	if input.Parts == nil || len(*input.Parts) == 0 {
+		return nil, "", emptyParam("Parts")
+	}
+
+	parts := make([]types.CompletedPart, 0, len(*input.Parts))
+	seen := map[int32]bool{}
+	for _, p := range *input.Parts {
+		n := aws.ToInt32(p.PartNumber)
+		if n < 1 || n > MaxUploadParts {
+			return nil, "", paramError("Parts", "part number %d must be between 1 and %d", n, MaxUploadParts)
+		}
+		if seen[n] {
+			return nil, "", paramError("Parts", "duplicate part number %d in 'Parts' param", n)
+		}
+		if strings.Trim(aws.ToString(p.ETag), `"`) == "" {
+			return nil, "", paramError("Parts", "empty ETag for part %d in 'Parts' param", n)
+		}
+		seen[n] = true
+
+		parts = append(parts, types.CompletedPart{
+			PartNumber: aws.Int32(n),
+			ETag:       aws.String(`"` + strings.Trim(*p.ETag, `"`) + `"`),
+		})
+	}
+	sortParts(parts)
+
+	enc, err := b.encryption(input.Encryption)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	uploaded, err := b.listParts(ctx, input.Key, input.UploadId, enc)
+	if err != nil {
+		return nil, "", err
+	}
+
+	stored := make(map[int32]types.Part, len(uploaded))
+	for _, p := range uploaded {
+		stored[aws.ToInt32(p.PartNumber)] = p
+	}
+
+	var total int64
+	for i, p := range parts {
+		s, ok := stored[*p.PartNumber]
+		if !ok {
+			return nil, "", paramError("Parts", "part %d was not uploaded", *p.PartNumber)
+		}
+		if strings.Trim(aws.ToString(s.ETag), `"`) != strings.Trim(*p.ETag, `"`) {
+			return nil, "", paramError("Parts", "ETag of part %d does not match the uploaded part", *p.PartNumber)
+		}
+		if i < len(parts)-1 && aws.ToInt64(s.Size) < MinPartSize {
+			return nil, "", paramError("Parts", "part %d is smaller than %d bytes", *p.PartNumber, MinPartSize)
+		}
+
+		total += aws.ToInt64(s.Size)
+	}
+
+	if input.Size != nil && total != *input.Size {
+		return nil, "", paramError("Size", "parts total %d bytes, expected %d", total, *input.Size)
+	}
+
+	complete := &s3.CompleteMultipartUploadInput{
+		Bucket:   b.Name,
+		Key:      input.Key,
+		UploadId: input.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: parts,
+		},
+	}
+	enc.applyCompleteMultipartUpload(complete)
+
+	out, err := b.Client.CompleteMultipartUpload(ctx, complete)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to complete multipart upload: %w", apiError(err))
+	}
+
+	return out, b.objectURL(ctx, *input.Key), nil
+}
+
+type BucketAbortMultipartUploadInput struct {
+	Key      *string
+	UploadId *string
+}
+
+// AbortMultipartUpload aborts an upload and deletes the parts uploaded so
+// far.
+func (b *Bucket) AbortMultipartUpload(input *BucketAbortMultipartUploadInput) (*s3.AbortMultipartUploadOutput, error) {
+	return b.AbortMultipartUploadContext(context.Background(), input)
+}
+
+func (b *Bucket) AbortMultipartUploadContext(ctx context.Context, input *BucketAbortMultipartUploadInput) (*s3.AbortMultipartUploadOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if *input == (BucketAbortMultipartUploadInput{}) {
+		return nil, emptyInput()
+	}
+	if input.Key == nil || *input.Key == "" {
+		return nil, emptyParam("Key")
+	}
+	if input.UploadId == nil || *input.UploadId == "" {
+		return nil, emptyParam("UploadId")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   b.Name,
+		Key:      input.Key,
+		UploadId: input.UploadId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to abort multipart upload: %w", apiError(err))
+	}
+
+	return out, nil
+}
+
+type BucketAbortStaleUploadsInput struct {
+	// OlderThan is how long ago an upload must have been started to be
+	// aborted.
+	OlderThan *time.Duration
+	Prefix    *string
+
+	// DryRun lists the stale uploads without aborting them.
+	DryRun *bool
+}
+
+// AbortStaleUploads aborts the multipart uploads started more than OlderThan
+// ago and returns the ones it aborted. Uploads completed or aborted by
+// someone else in the meantime are left out, and a failed abort does not
+// stop the others; their errors are joined. A lifecycle rule with
+// AbortIncompleteUploads does the same on S3's schedule.
+func (b *Bucket) AbortStaleUploads(input *BucketAbortStaleUploadsInput) ([]types.MultipartUpload, error) {
+	return b.AbortStaleUploadsContext(context.Background(), input)
+}
+
+func (b *Bucket) AbortStaleUploadsContext(ctx context.Context, input *BucketAbortStaleUploadsInput) ([]types.MultipartUpload, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if *input == (BucketAbortStaleUploadsInput{}) {
+		return nil, emptyInput()
+	}
+	if input.OlderThan == nil {
+		return nil, emptyParam("OlderThan")
+	}
+	if *input.OlderThan < 0 {
+		return nil, paramError("OlderThan", "'OlderThan' must not be negative")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cutoff := time.Now().Add(-*input.OlderThan)
+
+	var stale []types.MultipartUpload
+
+	params := &s3.ListMultipartUploadsInput{
+		Bucket: b.Name,
+		Prefix: input.Prefix,
+	}
+
+	for {
+		page, err := b.Client.ListMultipartUploads(ctx, params, withoutAccelerate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list multipart uploads: %w", apiError(err))
+		}
+
+		for _, u := range page.Uploads {
+			if u.Initiated != nil && u.Initiated.Before(cutoff) {
+				stale = append(stale, u)
+			}
+		}
+
+		if !aws.ToBool(page.IsTruncated) {
+			break
+		}
+
+		params.KeyMarker = page.NextKeyMarker
+		params.UploadIdMarker = page.NextUploadIdMarker
+	}
+
+	if aws.ToBool(input.DryRun) {
+		return stale, nil
+	}
+
+	var (
+		aborted []types.MultipartUpload
+		errs    []error
+	)
+
+	for _, u := range stale {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		_, err := b.Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   b.Name,
+			Key:      u.Key,
+			UploadId: u.UploadId,
+		})
+		err = apiError(err)
+
+		switch {
+		case err == nil:
+			aborted = append(aborted, u)
+		case errors.Is(err, ErrNotFound):
+		default:
+			errs = append(errs, fmt.Errorf("failed to abort multipart upload '%s': %w", aws.ToString(u.UploadId), err))
+		}
+	}
+
+	return aborted, errors.Join(errs...)
+}
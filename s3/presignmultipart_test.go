@@ -0,0 +1,319 @@
+package s3_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+
+	"github.com/itispx/goaws/s3"
+	"github.com/itispx/goaws/s3/s3test"
+)
+
+// putPart uploads data to a presigned part URL and returns the ETag S3
+// answers with.
+func putPart(part s3.PresignedPart, data []byte) (string, int, error) {
+	req, err := http.NewRequest(part.Method, part.URL, bytes.NewReader(data))
+	if err != nil {
+		return "", 0, err
+	}
+
+	for k, v := range part.Header {
+		if k != "Content-Length" {
+			req.Header.Set(k, v)
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	resp.Body.Close()
+
+	return resp.Header.Get("ETag"), resp.StatusCode, nil
+}
+
+func TestBucket_PresignUploadParts(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	key := "presigned-multipart"
+	data := bytes.Repeat([]byte("goaws"), int(s3.MinPartSize)/5+2)
+	duration := time.Minute
+
+	created, err := bct.StartMultipartUpload(&s3.BucketStartMultipartUploadInput{
+		Key: &key,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	parts, err := bct.PresignUploadParts(&s3.PresignUploadPartsInput{
+		Key:      &key,
+		UploadId: created.UploadId,
+		Duration: &duration,
+		Size:     aws.Int64(int64(len(data))),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(parts) != 2 || parts[0].Size != s3.MinPartSize || parts[1].Size != int64(len(data))-s3.MinPartSize {
+		t.Fatalf("unexpected parts %+v", parts)
+	}
+
+	_, status, err := putPart(parts[1], data[:5])
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if status != http.StatusForbidden {
+		t.Errorf("expected a part of the wrong size to be rejected, got %d", status)
+	}
+
+	var completed []types.CompletedPart
+	for _, p := range parts {
+		start := int64(p.PartNumber-1) * s3.MinPartSize
+
+		etag, status, err := putPart(p, data[start:start+p.Size])
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if status != http.StatusOK {
+			t.Fatalf("expected 200 for part %d, got %d", p.PartNumber, status)
+		}
+
+		completed = append(completed, types.CompletedPart{
+			PartNumber: aws.Int32(p.PartNumber),
+			ETag:       aws.String(etag),
+		})
+	}
+
+	_, _, err = bct.CompleteMultipartUpload(&s3.BucketCompleteMultipartUploadInput{
+		Key:      &key,
+		UploadId: created.UploadId,
+		Parts: &[]types.CompletedPart{
+			completed[0],
+			{PartNumber: aws.Int32(2), ETag: completed[0].ETag},
+		},
+	})
+	if err == nil || err.Error() != "ETag of part 2 does not match the uploaded part" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	_, _, err = bct.CompleteMultipartUpload(&s3.BucketCompleteMultipartUploadInput{
+		Key:      &key,
+		UploadId: created.UploadId,
+		Parts:    &completed,
+		Size:     aws.Int64(int64(len(data))),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	out, err := bct.GetObject(&s3.BucketGetObjectInput{
+		Key: &key,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	got, _ := io.ReadAll(out.Body)
+	out.Body.Close()
+
+	if !bytes.Equal(got, data) {
+		t.Errorf("expected %d bytes, got %d", len(data), len(got))
+	}
+
+	t.Cleanup(func() {
+		err = deleteObject(bucket, region, key)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func TestBucket_AbortStaleUploads(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	_, err = bct.StartMultipartUpload(&s3.BucketStartMultipartUploadInput{
+		Key: aws.String("stale"),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tests := []struct {
+		name      string
+		olderThan time.Duration
+		dryRun    bool
+		expected  int
+	}{
+		{"Recent", time.Hour, false, 0},
+		{"DryRun", 0, true, 1},
+		{"Abort", 0, false, 1},
+		{"Aborted", 0, false, 0},
+	}
+
+	for _, tt := range tests {
+		aborted, err := bct.AbortStaleUploads(&s3.BucketAbortStaleUploadsInput{
+			OlderThan: &tt.olderThan,
+			DryRun:    &tt.dryRun,
+		})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if len(aborted) != tt.expected {
+			t.Errorf("%s: expected %d uploads, got %d", tt.name, tt.expected, len(aborted))
+		}
+	}
+
+	t.Cleanup(func() {
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func TestBucket_AbortStaleUploadsContinues(t *testing.T) {
+	t.Parallel()
+
+	initiated := aws.Time(time.Now().Add(-time.Hour))
+
+	mock := &s3test.Mock{}
+	mock.On("ListMultipartUploads", &awss3.ListMultipartUploadsOutput{
+		Uploads: []types.MultipartUpload{
+			{Key: aws.String("a"), UploadId: aws.String("gone"), Initiated: initiated},
+			{Key: aws.String("b"), UploadId: aws.String("denied"), Initiated: initiated},
+			{Key: aws.String("c"), UploadId: aws.String("stale"), Initiated: initiated},
+		},
+	}, nil)
+	mock.On("AbortMultipartUpload", nil, &smithy.GenericAPIError{Code: "NoSuchUpload"})
+	mock.On("AbortMultipartUpload", nil, &smithy.GenericAPIError{Code: "AccessDenied"})
+	mock.On("AbortMultipartUpload", &awss3.AbortMultipartUploadOutput{}, nil)
+
+	bct := s3.Bucket{
+		Name:   aws.String("bucket-name"),
+		Client: mock,
+	}
+
+	aborted, err := bct.AbortStaleUploads(&s3.BucketAbortStaleUploadsInput{
+		OlderThan: aws.Duration(time.Minute),
+	})
+	if !errors.Is(err, s3.ErrAccessDenied) || errors.Is(err, s3.ErrNotFound) {
+		t.Errorf("expected only the denied abort to fail, got %v", err)
+	}
+
+	if len(aborted) != 1 || aws.ToString(aborted[0].UploadId) != "stale" {
+		t.Errorf("unexpected aborted uploads %+v", aborted)
+	}
+
+	if calls := mock.CallsTo("AbortMultipartUpload"); len(calls) != 3 {
+		t.Errorf("expected 3 aborts, got %d", len(calls))
+	}
+}
+
+func TestBucket_PresignedMultipartInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := "key-name"
+	uploadID := "upload-id"
+	duration := time.Minute
+	negative := -time.Minute
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		err  string
+	}{
+		{"PresignEmptyUploadId", func() error {
+			_, err := bct.PresignUploadParts(&s3.PresignUploadPartsInput{Key: &key, Duration: &duration})
+			return err
+		}, "empty 'UploadId' param"},
+		{"PresignTooManyParts", func() error {
+			_, err := bct.PresignUploadParts(&s3.PresignUploadPartsInput{Key: &key, UploadId: &uploadID, Duration: &duration, Size: aws.Int64(s3.MinPartSize*s3.MaxUploadParts + 1)})
+			return err
+		}, "'PartSize' is too small to upload 52428800001 bytes in at most 10000 parts"},
+		{"PresignPartNumber", func() error {
+			_, err := bct.PresignUploadParts(&s3.PresignUploadPartsInput{Key: &key, UploadId: &uploadID, Duration: &duration, Size: aws.Int64(10), PartNumbers: &[]int32{2}})
+			return err
+		}, "part number 2 must be between 1 and 1"},
+		{"PresignDuplicatePart", func() error {
+			_, err := bct.PresignUploadParts(&s3.PresignUploadPartsInput{Key: &key, UploadId: &uploadID, Duration: &duration, Size: aws.Int64(s3.MinPartSize * 2), PartNumbers: &[]int32{1, 2, 1}})
+			return err
+		}, "duplicate part number 1 in 'PartNumbers' param"},
+		{"CompleteEmptyParts", func() error {
+			_, _, err := bct.CompleteMultipartUpload(&s3.BucketCompleteMultipartUploadInput{Key: &key, UploadId: &uploadID})
+			return err
+		}, "empty 'Parts' param"},
+		{"CompleteDuplicatePart", func() error {
+			part := types.CompletedPart{PartNumber: aws.Int32(1), ETag: aws.String("etag")}
+			_, _, err := bct.CompleteMultipartUpload(&s3.BucketCompleteMultipartUploadInput{Key: &key, UploadId: &uploadID, Parts: &[]types.CompletedPart{part, part}})
+			return err
+		}, "duplicate part number 1 in 'Parts' param"},
+		{"CompleteEmptyETag", func() error {
+			part := types.CompletedPart{PartNumber: aws.Int32(1), ETag: aws.String(`""`)}
+			_, _, err := bct.CompleteMultipartUpload(&s3.BucketCompleteMultipartUploadInput{Key: &key, UploadId: &uploadID, Parts: &[]types.CompletedPart{part}})
+			return err
+		}, "empty ETag for part 1 in 'Parts' param"},
+		{"AbortStaleNegative", func() error {
+			_, err := bct.AbortStaleUploads(&s3.BucketAbortStaleUploadsInput{OlderThan: &negative})
+			return err
+		}, "'OlderThan' must not be negative"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.call()
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("expected '%s', got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,390 @@
+package s3
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+var ErrSourceChanged = errors.New("source file changed since checkpoint")
+
+// Checkpoint records the progress of a resumable upload.
+type Checkpoint struct {
+	Bucket   string           `json:"bucket"`
+	Key      string           `json:"key"`
+	UploadID string           `json:"upload_id"`
+	PartSize int64            `json:"part_size"`
+	Size     int64            `json:"size"`
+	ModTime  time.Time        `json:"mod_time"`
+	Parts    []CheckpointPart `json:"parts"`
+}
+
+type CheckpointPart struct {
+	PartNumber int32  `json:"part_number"`
+	ETag       string `json:"etag"`
+}
+
+// CheckpointStore persists checkpoints between runs. Load returns a nil
+// Checkpoint and a nil error when there is nothing saved under id.
+type CheckpointStore interface {
+	Load(id string) (*Checkpoint, error)
+	Save(id string, cp *Checkpoint) error
+	Delete(id string) error
+}
+
+// FileCheckpointStore keeps each checkpoint as a JSON file in Dir.
+type FileCheckpointStore struct {
+	Dir string
+}
+
+func (s *FileCheckpointStore) path(id string) string {
+	sum := sha256.Sum256([]byte(id))
+
+	return filepath.Join(s.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (s *FileCheckpointStore) Load(id string) (*Checkpoint, error) {
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	cp := &Checkpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint: %w", err)
+	}
+
+	return cp, nil
+}
+
+func (s *FileCheckpointStore) Save(id string, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create checkpoint dir: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a torn
+	// checkpoint behind.
+	tmp, err := os.CreateTemp(s.Dir, ".checkpoint-*")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path(id))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileCheckpointStore) Delete(id string) error {
+	err := os.Remove(s.path(id))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+type BucketUploadResumableInput struct {
+	Path        *string
+	Key         *string
+	PartSize    *int64
+	Concurrency *int
+	Store       CheckpointStore
+	Encryption  *Encryption
+	*s3.CreateMultipartUploadInput
+}
+
+// UploadResumable uploads the file at Path as a multipart upload and records
+// every finished part in Store. If a checkpoint for the same bucket and key
+// exists, the upload continues from the parts S3 already has, provided the
+// file's size and modification time still match the checkpoint; otherwise
+// ErrSourceChanged is returned. The checkpoint is deleted once the upload
+// completes. Unlike UploadMultipart, a failed upload is left open so it can
+// be resumed.
+func (b *Bucket) UploadResumable(input *BucketUploadResumableInput) (*s3.CompleteMultipartUploadOutput, string, error) {
+	return b.UploadResumableContext(context.Background(), input)
+}
+
+func (b *Bucket) UploadResumableContext(ctx context.Context, input *BucketUploadResumableInput) (*s3.CompleteMultipartUploadOutput, string, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, "", emptyParam("Name")
+	}
+	if input == nil {
+		return nil, "", nilInput()
+	}
+	if *input == (BucketUploadResumableInput{}) {
+		return nil, "", emptyInput()
+	}
+	if input.Path == nil || *input.Path == "" {
+		return nil, "", emptyParam("Path")
+	}
+	if input.Key == nil || *input.Key == "" {
+		return nil, "", emptyParam("Key")
+	}
+	if input.Store == nil {
+		return nil, "", emptyParam("Store")
+	}
+
+	partSize, concurrency, err := multipartSettings(input.PartSize, input.Concurrency)
+	if err != nil {
+		return nil, "", err
+	}
+
+	enc, err := b.encryption(input.Encryption)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	f, err := os.Open(*input.Path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	id := *b.Name + "/" + *input.Key
+
+	cp, err := input.Store.Load(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if cp != nil {
+		if cp.Size != info.Size() || !cp.ModTime.Equal(info.ModTime()) {
+			return nil, "", ErrSourceChanged
+		}
+
+		cp.Parts, err = b.listUploadedParts(ctx, input.Key, &cp.UploadID, enc)
+
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchUpload" {
+			// The upload was aborted or expired, start over.
+			cp, err = nil, nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	if cp == nil {
+		if (info.Size()+partSize-1)/partSize > MaxUploadParts {
+			return nil, "", fmt.Errorf("file exceeds %d parts of %d bytes", MaxUploadParts, partSize)
+		}
+
+		if input.CreateMultipartUploadInput == nil {
+			input.CreateMultipartUploadInput = &s3.CreateMultipartUploadInput{}
+		}
+
+		input.CreateMultipartUploadInput.Key = input.Key
+		input.CreateMultipartUploadInput.Bucket = b.Name
+
+		enc.applyCreateMultipartUpload(input.CreateMultipartUploadInput)
+
+		created, err := b.Client.CreateMultipartUpload(ctx, input.CreateMultipartUploadInput)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create multipart upload: %w", apiError(err))
+		}
+
+		cp = &Checkpoint{
+			Bucket:   *b.Name,
+			Key:      *input.Key,
+			UploadID: *created.UploadId,
+			PartSize: partSize,
+			Size:     info.Size(),
+			ModTime:  info.ModTime(),
+		}
+	}
+
+	if err := input.Store.Save(id, cp); err != nil {
+		return nil, "", err
+	}
+
+	parts, err := b.uploadMissingParts(ctx, f, input.Key, cp, concurrency, enc, func(cp *Checkpoint) error {
+		return input.Store.Save(id, cp)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	complete := &s3.CompleteMultipartUploadInput{
+		Bucket:   b.Name,
+		Key:      input.Key,
+		UploadId: aws.String(cp.UploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: parts,
+		},
+	}
+	enc.applyCompleteMultipartUpload(complete)
+
+	out, err := b.Client.CompleteMultipartUpload(ctx, complete)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to complete multipart upload: %w", apiError(err))
+	}
+
+	if err := input.Store.Delete(id); err != nil {
+		return nil, "", err
+	}
+
+	return out, b.objectURL(ctx, *input.Key), nil
+}
+
+// listUploadedParts returns the parts S3 has stored for the upload. S3 is
+// the source of truth, since a part may have finished after the last
+// checkpoint was written.
+func (b *Bucket) listUploadedParts(ctx context.Context, key, uploadID *string, enc *Encryption) ([]CheckpointPart, error) {
+	listed, err := b.listParts(ctx, key, uploadID, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]CheckpointPart, 0, len(listed))
+	for _, p := range listed {
+		parts = append(parts, CheckpointPart{
+			PartNumber: *p.PartNumber,
+			ETag:       *p.ETag,
+		})
+	}
+
+	return parts, nil
+}
+
+// listParts returns every part S3 has stored for the upload.
+func (b *Bucket) listParts(ctx context.Context, key, uploadID *string, enc *Encryption) ([]types.Part, error) {
+	var parts []types.Part
+
+	params := &s3.ListPartsInput{
+		Bucket:   b.Name,
+		Key:      key,
+		UploadId: uploadID,
+	}
+	enc.applyListParts(params)
+
+	paginator := s3.NewListPartsPaginator(b.Client, params)
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list parts: %w", apiError(err))
+		}
+
+		parts = append(parts, page.Parts...)
+	}
+
+	return parts, nil
+}
+
+// uploadMissingParts uploads every part of f that is not yet in cp, calling
+// save after each one, and returns the full list of parts to complete with.
+func (b *Bucket) uploadMissingParts(ctx context.Context, f io.ReaderAt, key *string, cp *Checkpoint, concurrency int, enc *Encryption, save func(*Checkpoint) error) ([]types.CompletedPart, error) {
+	w, ctx := newWorkers(ctx)
+
+	total := int32((cp.Size + cp.PartSize - 1) / cp.PartSize)
+	if total == 0 {
+		total = 1
+	}
+
+	done := map[int32]bool{}
+	for _, p := range cp.Parts {
+		done[p.PartNumber] = true
+	}
+
+	var mu sync.Mutex
+
+	numbers := make(chan int32)
+
+	w.start(concurrency, func() {
+		for n := range numbers {
+			offset := int64(n-1) * cp.PartSize
+			size := min(cp.PartSize, cp.Size-offset)
+
+			part, err := b.uploadPart(ctx, key, aws.String(cp.UploadID), n, io.NewSectionReader(f, offset, size), size, enc)
+
+			mu.Lock()
+			if err == nil {
+				cp.Parts = append(cp.Parts, CheckpointPart{
+					PartNumber: n,
+					ETag:       *part.ETag,
+				})
+				err = save(cp)
+			}
+			mu.Unlock()
+
+			if err != nil {
+				w.fail(err)
+			}
+		}
+	})
+
+	for n := int32(1); n <= total; n++ {
+		if done[n] {
+			continue
+		}
+
+		select {
+		case numbers <- n:
+			continue
+		case <-ctx.Done():
+		}
+
+		break
+	}
+
+	close(numbers)
+
+	if err := w.wait(); err != nil {
+		return nil, err
+	}
+
+	parts := make([]types.CompletedPart, 0, len(cp.Parts))
+	for _, p := range cp.Parts {
+		parts = append(parts, types.CompletedPart{
+			ETag:       aws.String(p.ETag),
+			PartNumber: aws.Int32(p.PartNumber),
+		})
+	}
+
+	sortParts(parts)
+
+	return parts, nil
+}
@@ -0,0 +1,285 @@
+package s3_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/itispx/goaws/s3"
+)
+
+func writeTempFile(t *testing.T, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "source")
+
+	err := os.WriteFile(path, data, 0o644)
+	if err != nil {
+		t.Fatalf("setup fail: %s", err.Error())
+	}
+
+	return path
+}
+
+func TestBucket_UploadResumable(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	data := bytes.Repeat([]byte("goaws"), int(s3.MinPartSize)/2)
+	path := writeTempFile(t, data)
+	key := "resumable-test"
+
+	svc, err := getSVC(region)
+	if err != nil {
+		t.Fatalf("setup fail: %s", err.Error())
+	}
+
+	// Start an upload and send the first part, as if a previous run had
+	// died halfway through.
+	created, err := svc.CreateMultipartUpload(context.TODO(), &awss3.CreateMultipartUploadInput{
+		Bucket: &bucket,
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		t.Fatalf("setup fail: %s", err.Error())
+	}
+
+	_, err = svc.UploadPart(context.TODO(), &awss3.UploadPartInput{
+		Bucket:     &bucket,
+		Key:        aws.String(key),
+		UploadId:   created.UploadId,
+		PartNumber: aws.Int32(1),
+		Body:       bytes.NewReader(data[:s3.MinPartSize]),
+	})
+	if err != nil {
+		t.Fatalf("setup fail: %s", err.Error())
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("setup fail: %s", err.Error())
+	}
+
+	store := &s3.FileCheckpointStore{Dir: t.TempDir()}
+
+	err = store.Save(bucket+"/"+key, &s3.Checkpoint{
+		Bucket:   bucket,
+		Key:      key,
+		UploadID: *created.UploadId,
+		PartSize: s3.MinPartSize,
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+	})
+	if err != nil {
+		t.Fatalf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	_, _, err = bct.UploadResumable(&s3.BucketUploadResumableInput{
+		Path:  &path,
+		Key:   &key,
+		Store: store,
+	})
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	out, err := svc.ListParts(context.TODO(), &awss3.ListPartsInput{
+		Bucket:   &bucket,
+		Key:      aws.String(key),
+		UploadId: created.UploadId,
+	})
+	if err == nil {
+		t.Errorf("expected upload to be completed, %d parts still pending", len(out.Parts))
+	}
+
+	cp, err := store.Load(bucket + "/" + key)
+	if err != nil || cp != nil {
+		t.Error("expected checkpoint to be deleted")
+	}
+
+	t.Cleanup(func() {
+		err = deleteObject(bucket, region, key)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func TestBucket_UploadResumableSourceChanged(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	region := "us-east-1"
+	key := "key-name"
+	path := writeTempFile(t, []byte("goaws"))
+
+	store := &s3.FileCheckpointStore{Dir: t.TempDir()}
+
+	err := store.Save(name+"/"+key, &s3.Checkpoint{
+		Bucket:   name,
+		Key:      key,
+		UploadID: "upload-id",
+		PartSize: s3.MinPartSize,
+		Size:     4,
+		ModTime:  time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("setup fail: %s", err.Error())
+	}
+
+	bct := s3.Bucket{
+		Name:   &name,
+		Region: &region,
+	}
+
+	_, _, err = bct.UploadResumable(&s3.BucketUploadResumableInput{
+		Path:  &path,
+		Key:   &key,
+		Store: store,
+	})
+	if !errors.Is(err, s3.ErrSourceChanged) {
+		t.Errorf("expected ErrSourceChanged, got %v", err)
+	}
+}
+
+func TestBucket_UploadResumableNilInput(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.UploadResumable(nil)
+	if err == nil || err.Error() != "nil input" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_UploadResumableEmptyInput(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.UploadResumable(&s3.BucketUploadResumableInput{})
+	if err == nil || err.Error() != "empty input" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_UploadResumableNilPath(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := "key-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.UploadResumable(&s3.BucketUploadResumableInput{
+		Key: &key,
+	})
+	if err == nil || err.Error() != "empty 'Path' param" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_UploadResumableNilStore(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := "key-name"
+	path := "source"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.UploadResumable(&s3.BucketUploadResumableInput{
+		Path: &path,
+		Key:  &key,
+	})
+	if err == nil || err.Error() != "empty 'Store' param" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	t.Parallel()
+
+	store := &s3.FileCheckpointStore{Dir: filepath.Join(t.TempDir(), "checkpoints")}
+	id := "bucket-name/key-name"
+
+	cp, err := store.Load(id)
+	if err != nil || cp != nil {
+		t.Fatalf("expected no checkpoint, got %v, %v", cp, err)
+	}
+
+	want := &s3.Checkpoint{
+		Bucket:   "bucket-name",
+		Key:      "key-name",
+		UploadID: "upload-id",
+		PartSize: s3.MinPartSize,
+		Size:     s3.MinPartSize * 3,
+		ModTime:  time.Date(2024, 7, 1, 12, 0, 0, 123, time.UTC),
+		Parts: []s3.CheckpointPart{
+			{PartNumber: 1, ETag: `"etag-1"`},
+			{PartNumber: 3, ETag: `"etag-3"`},
+		},
+	}
+
+	err = store.Save(id, want)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	got, err := store.Load(id)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	err = store.Delete(id)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	cp, err = store.Load(id)
+	if err != nil || cp != nil {
+		t.Errorf("expected no checkpoint after delete, got %v, %v", cp, err)
+	}
+}
@@ -4,83 +4,155 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
-	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
-	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 type Bucket struct {
 	Name   *string `json:"name"`
 	Region *string `json:"region"`
-	Client *s3.Client
+	Client API
+
+	// Accelerate and DualStack are passed to NewSession when the bucket
+	// creates its own client.
+	Accelerate *bool `json:"accelerate,omitempty"`
+	DualStack  *bool `json:"dualStack,omitempty"`
+
+	// Encryption is applied to object requests that do not set their own.
+	Encryption *Encryption `json:"-"`
 }
 
 type NewSessionInput struct {
 	Region *string
+
+	// Accelerate sends object uploads, downloads and copies through the S3
+	// Transfer Acceleration endpoint. Acceleration must be enabled on the
+	// bucket. Bucket operations, such as creating a bucket, changing its
+	// configuration or listing its objects, always use the regional endpoint.
+	Accelerate *bool
+
+	// DualStack uses the endpoints that accept both IPv4 and IPv6.
+	DualStack *bool
 }
 
-func NewSession(input *NewSessionInput) (*s3.Client, error) {
+// NewSession creates a client for input.Region. Options can point it at
+// another endpoint or change how credentials, retries and HTTP are handled.
+func NewSession(input *NewSessionInput, opts ...SessionOption) (*s3.Client, error) {
+	return NewSessionContext(context.Background(), input, opts...)
+}
+
+// NewSessionContext is like NewSession but uses ctx while loading the
+// config, which can involve requests for credentials.
+func NewSessionContext(ctx context.Context, input *NewSessionInput, opts ...SessionOption) (*s3.Client, error) {
 	if input == nil {
-		return nil, fmt.Errorf("nil input")
+		return nil, nilInput()
 	}
 	if *input == (NewSessionInput{}) {
-		return nil, fmt.Errorf("empty input")
+		return nil, emptyInput()
 	}
 	if input.Region == nil || *input.Region == "" {
-		return nil, fmt.Errorf("empty 'Region' param")
+		return nil, emptyParam("Region")
+	}
+
+	options := &sessionOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if err := options.validate(); err != nil {
+		return nil, err
 	}
 
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(*input.Region))
+	cfg, err := options.loadConfig(ctx, *input.Region)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load SDK config: %w", err)
 	}
 
-	svc := s3.NewFromConfig(cfg)
+	svc := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.UseAccelerate = aws.ToBool(input.Accelerate)
+		if aws.ToBool(input.DualStack) {
+			o.EndpointOptions.UseDualStackEndpoint = aws.DualStackEndpointStateEnabled
+		}
+
+		options.apply(o)
+	})
 
 	return svc, nil
 }
 
-func (b *Bucket) NewSession() (*s3.Client, error) {
+// presignClient presigns with the bucket's client, or with one built from its
+// options when Client is a mock.
+func (b *Bucket) presignClient() *s3.PresignClient {
+	svc, ok := b.Client.(*s3.Client)
+	if !ok {
+		svc = s3.New(b.Client.Options())
+	}
+
+	return s3.NewPresignClient(svc)
+}
+
+// withoutAccelerate is passed to every operation that does not transfer
+// object data, which the accelerate endpoint does not serve.
+func withoutAccelerate(o *s3.Options) {
+	o.UseAccelerate = false
+}
+
+// NewSession creates the bucket's client. Operations call it with no options
+// when Client is nil, so call it first to use any.
+func (b *Bucket) NewSession(opts ...SessionOption) (*s3.Client, error) {
+	return b.NewSessionContext(context.Background(), opts...)
+}
+
+// NewSessionContext is like NewSession but uses ctx while loading the config.
+func (b *Bucket) NewSessionContext(ctx context.Context, opts ...SessionOption) (*s3.Client, error) {
 	if *b == (Bucket{}) {
-		return nil, fmt.Errorf("empty input")
+		return nil, emptyInput()
 	}
 	if b.Region == nil || *b.Region == "" {
-		return nil, fmt.Errorf("empty 'Region' param")
+		return nil, emptyParam("Region")
 	}
 
-	svc, err := NewSession(&NewSessionInput{
-		Region: b.Region,
-	})
+	svc, err := NewSessionContext(ctx, &NewSessionInput{
+		Region:     b.Region,
+		Accelerate: b.Accelerate,
+		DualStack:  b.DualStack,
+	}, opts...)
 	if err != nil {
 		return nil, err
 	}
 
 	b.Client = svc
 
-	return b.Client, nil
+	return svc, nil
 }
 
 type ListBucketsInput struct {
-	SVC    *s3.Client
+	SVC    API
 	Region *string
 }
 
+// ListBuckets lists the buckets owned by the caller.
 func ListBuckets(input *ListBucketsInput) (*s3.ListBucketsOutput, error) {
+	return ListBucketsContext(context.Background(), input)
+}
+
+func ListBucketsContext(ctx context.Context, input *ListBucketsInput) (*s3.ListBucketsOutput, error) {
 	if input == nil {
-		return nil, fmt.Errorf("nil input")
+		return nil, nilInput()
 	}
 	if *input == (ListBucketsInput{}) {
-		return nil, fmt.Errorf("empty input")
+		return nil, emptyInput()
 	}
 	if input.Region == nil || *input.Region == "" {
-		return nil, fmt.Errorf("empty 'Region' param")
+		return nil, emptyParam("Region")
 	}
 
 	if input.SVC == nil {
 		var err error
-		input.SVC, err = NewSession(&NewSessionInput{
+		input.SVC, err = NewSessionContext(ctx, &NewSessionInput{
 			Region: input.Region,
 		})
 		if err != nil {
@@ -88,9 +160,9 @@ func ListBuckets(input *ListBucketsInput) (*s3.ListBucketsOutput, error) {
 		}
 	}
 
-	resp, err := input.SVC.ListBuckets(context.TODO(), &s3.ListBucketsInput{})
+	resp, err := input.SVC.ListBuckets(ctx, &s3.ListBucketsInput{}, withoutAccelerate)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list buckets: %w", err)
+		return nil, fmt.Errorf("failed to list buckets: %w", apiError(err))
 	}
 
 	return resp, nil
@@ -100,13 +172,18 @@ type BucketCreateInput struct {
 	*s3.CreateBucketInput
 }
 
+// Create creates the bucket in its region.
 func (b *Bucket) Create(input *BucketCreateInput) (*s3.CreateBucketOutput, error) {
+	return b.CreateContext(context.Background(), input)
+}
+
+func (b *Bucket) CreateContext(ctx context.Context, input *BucketCreateInput) (*s3.CreateBucketOutput, error) {
 	if b.Name == nil || *b.Name == "" {
-		return nil, fmt.Errorf("empty 'Name' param")
+		return nil, emptyParam("Name")
 	}
 
 	if b.Client == nil {
-		_, err := b.NewSession()
+		_, err := b.NewSessionContext(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -122,22 +199,30 @@ func (b *Bucket) Create(input *BucketCreateInput) (*s3.CreateBucketOutput, error
 
 	input.Bucket = b.Name
 
-	out, err := b.Client.CreateBucket(context.TODO(), input.CreateBucketInput)
+	out, err := b.Client.CreateBucket(ctx, input.CreateBucketInput, withoutAccelerate)
 
-	return out, err
+	return out, apiError(err)
 }
 
 type BucketDeleteInput struct {
+	// Force empties the bucket first, deleting every object version and
+	// delete marker in it.
+	Force *bool
 	*s3.DeleteBucketInput
 }
 
+// Delete deletes the bucket, which has to be empty unless Force is set.
 func (b *Bucket) Delete(input *BucketDeleteInput) (*s3.DeleteBucketOutput, error) {
+	return b.DeleteContext(context.Background(), input)
+}
+
+func (b *Bucket) DeleteContext(ctx context.Context, input *BucketDeleteInput) (*s3.DeleteBucketOutput, error) {
 	if b.Name == nil || *b.Name == "" {
-		return nil, fmt.Errorf("empty 'Name' param")
+		return nil, emptyParam("Name")
 	}
 
 	if b.Client == nil {
-		_, err := b.NewSession()
+		_, err := b.NewSessionContext(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -151,38 +236,60 @@ func (b *Bucket) Delete(input *BucketDeleteInput) (*s3.DeleteBucketOutput, error
 		input.DeleteBucketInput = &s3.DeleteBucketInput{}
 	}
 
+	if aws.ToBool(input.Force) {
+		emptied, err := b.EmptyContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := emptied.Err(); err != nil {
+			return nil, err
+		}
+	}
+
 	input.Bucket = b.Name
 
-	out, err := b.Client.DeleteBucket(context.TODO(), input.DeleteBucketInput)
+	out, err := b.Client.DeleteBucket(ctx, input.DeleteBucketInput, withoutAccelerate)
 
-	return out, err
+	return out, apiError(err)
 }
 
 type BucketUploadObjectInput struct {
-	File *[]byte
-	Key  *string
+	File       *[]byte
+	Key        *string
+	Encryption *Encryption
+	ACL        types.ObjectCannedACL
 	*s3.PutObjectInput
 }
 
+// UploadObject uploads an object in a single request and returns its URL.
 func (b *Bucket) UploadObject(input *BucketUploadObjectInput) (*s3.PutObjectOutput, string, error) {
+	return b.UploadObjectContext(context.Background(), input)
+}
+
+func (b *Bucket) UploadObjectContext(ctx context.Context, input *BucketUploadObjectInput) (*s3.PutObjectOutput, string, error) {
 	if b.Name == nil || *b.Name == "" {
-		return nil, "", fmt.Errorf("empty 'Name' param")
+		return nil, "", emptyParam("Name")
 	}
 	if input == nil {
-		return nil, "", fmt.Errorf("nil input")
+		return nil, "", nilInput()
 	}
 	if *input == (BucketUploadObjectInput{}) {
-		return nil, "", fmt.Errorf("empty input")
+		return nil, "", emptyInput()
 	}
 	if input.File == nil {
-		return nil, "", fmt.Errorf("empty 'File' param")
+		return nil, "", emptyParam("File")
 	}
 	if input.Key == nil || *input.Key == "" {
-		return nil, "", fmt.Errorf("empty 'Key' param")
+		return nil, "", emptyParam("Key")
+	}
+
+	enc, err := b.encryption(input.Encryption)
+	if err != nil {
+		return nil, "", err
 	}
 
 	if b.Client == nil {
-		_, err := b.NewSession()
+		_, err := b.NewSessionContext(ctx)
 		if err != nil {
 			return nil, "", err
 		}
@@ -194,37 +301,90 @@ func (b *Bucket) UploadObject(input *BucketUploadObjectInput) (*s3.PutObjectOutp
 		}
 	}
 
+	if input.ACL != "" {
+		input.PutObjectInput.ACL = input.ACL
+	}
+
 	input.Body = bytes.NewReader(*input.File)
 	input.Bucket = b.Name
 
-	out, err := b.Client.PutObject(context.TODO(), input.PutObjectInput)
+	enc.applyPutObject(input.PutObjectInput)
+
+	out, err := b.Client.PutObject(ctx, input.PutObjectInput)
+
+	return out, b.objectURL(ctx, *input.Key), apiError(err)
+}
+
+// objectURL returns the URL of key on the endpoint the client sends requests
+// to, taking acceleration, dual-stack and custom endpoints into account.
+func (b *Bucket) objectURL(ctx context.Context, key string) string {
+	return b.bucketURL(ctx) + "/" + key
+}
+
+// bucketURL is like objectURL but returns the URL of the bucket itself.
+func (b *Bucket) bucketURL(ctx context.Context) string {
+	o := b.Client.Options()
+
+	params := s3.EndpointParameters{
+		Bucket:         b.Name,
+		Region:         aws.String(o.Region),
+		Endpoint:       o.BaseEndpoint,
+		ForcePathStyle: aws.Bool(o.UsePathStyle),
+		Accelerate:     aws.Bool(o.UseAccelerate),
+		UseDualStack:   aws.Bool(o.EndpointOptions.UseDualStackEndpoint == aws.DualStackEndpointStateEnabled),
+		UseFIPS:        aws.Bool(o.EndpointOptions.UseFIPSEndpoint == aws.FIPSEndpointStateEnabled),
+	}
+	if o.Region == "" {
+		params.Region = b.Region
+	}
+
+	resolver := o.EndpointResolverV2
+	if resolver == nil {
+		resolver = s3.NewDefaultEndpointResolverV2()
+	}
 
-	url := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", *b.Name, *b.Region, *input.Key)
+	endpoint, err := resolver.ResolveEndpoint(ctx, params)
+	if err != nil {
+		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com", *b.Name, aws.ToString(params.Region))
+	}
 
-	return out, url, err
+	return strings.TrimSuffix(endpoint.URI.String(), "/")
 }
 
 type BucketGetObjectInput struct {
-	Key *string
+	Key        *string
+	VersionId  *string
+	Encryption *Encryption
 	*s3.GetObjectInput
 }
 
+// GetObject gets an object or one of its versions. The caller has to close
+// its Body.
 func (b *Bucket) GetObject(input *BucketGetObjectInput) (*s3.GetObjectOutput, error) {
+	return b.GetObjectContext(context.Background(), input)
+}
+
+func (b *Bucket) GetObjectContext(ctx context.Context, input *BucketGetObjectInput) (*s3.GetObjectOutput, error) {
 	if b.Name == nil || *b.Name == "" {
-		return nil, fmt.Errorf("empty 'Name' param")
+		return nil, emptyParam("Name")
 	}
 	if input == nil {
-		return nil, fmt.Errorf("nil input")
+		return nil, nilInput()
 	}
 	if *input == (BucketGetObjectInput{}) {
-		return nil, fmt.Errorf("empty input")
+		return nil, emptyInput()
 	}
 	if input.Key == nil || *input.Key == "" {
-		return nil, fmt.Errorf("empty 'Key' param")
+		return nil, emptyParam("Key")
+	}
+
+	enc, err := b.encryption(input.Encryption)
+	if err != nil {
+		return nil, err
 	}
 
 	if b.Client == nil {
-		_, err := b.NewSession()
+		_, err := b.NewSessionContext(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -236,34 +396,46 @@ func (b *Bucket) GetObject(input *BucketGetObjectInput) (*s3.GetObjectOutput, er
 		}
 	}
 
+	if input.VersionId != nil {
+		input.GetObjectInput.VersionId = input.VersionId
+	}
+
 	input.Bucket = b.Name
 
-	out, err := b.Client.GetObject(context.TODO(), input.GetObjectInput)
+	enc.applyGetObject(input.GetObjectInput)
 
-	return out, err
+	out, err := b.Client.GetObject(ctx, input.GetObjectInput)
+
+	return out, apiError(err)
 }
 
 type BucketDeleteObjectInput struct {
-	Key *string
+	Key       *string
+	VersionId *string
 	*s3.DeleteObjectInput
 }
 
+// DeleteObject deletes an object or one of its versions.
 func (b *Bucket) DeleteObject(input *BucketDeleteObjectInput) (*s3.DeleteObjectOutput, error) {
+	return b.DeleteObjectContext(context.Background(), input)
+}
+
+func (b *Bucket) DeleteObjectContext(ctx context.Context, input *BucketDeleteObjectInput) (*s3.DeleteObjectOutput, error) {
 	if b.Name == nil || *b.Name == "" {
-		return nil, fmt.Errorf("empty 'Name' param")
+		return nil, emptyParam("Name")
 	}
 	if input == nil {
-		return nil, fmt.Errorf("nil input")
+		return nil, nilInput()
 	}
 	if *input == (BucketDeleteObjectInput{}) {
-		return nil, fmt.Errorf("empty input")
+		return nil, emptyInput()
 	}
 	if input.Key == nil || *input.Key == "" {
-		return nil, fmt.Errorf("empty 'Key' param")
+		return nil, emptyParam("Key")
 	}
 
 	if b.Client == nil {
-		_, err := b.NewSession()
+		_, err := b.NewSessionContext(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -275,11 +447,15 @@ func (b *Bucket) DeleteObject(input *BucketDeleteObjectInput) (*s3.DeleteObjectO
 		}
 	}
 
+	if input.VersionId != nil {
+		input.DeleteObjectInput.VersionId = input.VersionId
+	}
+
 	input.Bucket = b.Name
 
-	out, err := b.Client.DeleteObject(context.TODO(), input.DeleteObjectInput)
+	out, err := b.Client.DeleteObject(ctx, input.DeleteObjectInput)
 
-	return out, err
+	return out, apiError(err)
 }
 
 type ListObjectsInput struct {
@@ -289,13 +465,19 @@ type ListObjectsInput struct {
 	*s3.ListObjectsV2Input
 }
 
+// ListObjects returns a single page of objects. Use IterateObjects or Objects
+// to walk every page.
 func (b *Bucket) ListObjects(input *ListObjectsInput) (*s3.ListObjectsV2Output, error) {
+	return b.ListObjectsContext(context.Background(), input)
+}
+
+func (b *Bucket) ListObjectsContext(ctx context.Context, input *ListObjectsInput) (*s3.ListObjectsV2Output, error) {
 	if b.Name == nil || *b.Name == "" {
-		return nil, fmt.Errorf("empty 'Name' param")
+		return nil, emptyParam("Name")
 	}
 
 	if b.Client == nil {
-		_, err := b.NewSession()
+		_, err := b.NewSessionContext(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -311,92 +493,163 @@ func (b *Bucket) ListObjects(input *ListObjectsInput) (*s3.ListObjectsV2Output,
 
 	input.Bucket = b.Name
 
+	if input.Prefix != nil {
+		input.ListObjectsV2Input.Prefix = input.Prefix
+	}
+	if input.StartAfter != nil {
+		input.ListObjectsV2Input.StartAfter = input.StartAfter
+	}
+
 	if input.Limit != nil {
 		limit := int32(*input.Limit)
 		input.MaxKeys = &limit
 	}
 
-	out, err := b.Client.ListObjectsV2(context.TODO(), input.ListObjectsV2Input)
+	out, err := b.Client.ListObjectsV2(ctx, input.ListObjectsV2Input, withoutAccelerate)
 
-	return out, err
+	return out, apiError(err)
 }
 
 type PresignGetInput struct {
-	Key      *string
-	Duration *time.Duration
+	Key        *string
+	VersionId  *string
+	Duration   *time.Duration
+	Encryption *Encryption
+
+	// PresignOptions can replace the presigner or add client options. Its
+	// Expires is ignored in favor of Duration.
 	*s3.PresignOptions
+
+	// GetObjectInput sets other request parameters, such as the
+	// ResponseContentDisposition and ResponseContentType overrides.
+	*s3.GetObjectInput
 }
 
-func (b *Bucket) PresignGet(input *PresignGetInput) (*v4.PresignedHTTPRequest, error) {
+// PresignGet presigns a GET request for an object.
+func (b *Bucket) PresignGet(input *PresignGetInput) (*PresignedRequest, error) {
+	return b.PresignGetContext(context.Background(), input)
+}
+
+func (b *Bucket) PresignGetContext(ctx context.Context, input *PresignGetInput) (*PresignedRequest, error) {
 	if b.Name == nil || *b.Name == "" {
-		return nil, fmt.Errorf("empty 'Name' param")
+		return nil, emptyParam("Name")
 	}
 	if input == nil {
-		return nil, fmt.Errorf("nil input")
+		return nil, nilInput()
 	}
 	if *input == (PresignGetInput{}) {
-		return nil, fmt.Errorf("empty input")
+		return nil, emptyInput()
 	}
 	if input.Key == nil || *input.Key == "" {
-		return nil, fmt.Errorf("empty 'Key' param")
+		return nil, emptyParam("Key")
 	}
-	if input.Duration == nil {
-		return nil, fmt.Errorf("empty 'Duration' param")
+	if err := validatePresignDuration(input.Duration); err != nil {
+		return nil, err
+	}
+
+	enc, err := b.encryption(input.Encryption)
+	if err != nil {
+		return nil, err
 	}
 
 	if b.Client == nil {
-		_, err := b.NewSession()
+		_, err := b.NewSessionContext(ctx)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	presignClient := s3.NewPresignClient(b.Client)
+	params := &s3.GetObjectInput{}
+	if input.GetObjectInput != nil {
+		*params = *input.GetObjectInput
+	}
+
+	params.Bucket = b.Name
+	params.Key = input.Key
+	if input.VersionId != nil {
+		params.VersionId = input.VersionId
+	}
+	enc.applyGetObject(params)
 
-	out, err := presignClient.PresignGetObject(context.TODO(), &s3.GetObjectInput{
-		Bucket: b.Name,
-		Key:    input.Key,
-	}, s3.WithPresignExpires(*input.Duration))
+	expires := time.Now().Add(*input.Duration)
 
-	return out, err
+	out, err := b.presignClient().PresignGetObject(ctx, params, presignOptions(input.PresignOptions, *input.Duration))
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign request: %w", apiError(err))
+	}
+
+	return newPresignedRequest(out, expires), nil
 }
 
 type PresignPutInput struct {
-	Key      *string
-	Duration *time.Duration
+	Key        *string
+	Duration   *time.Duration
+	Encryption *Encryption
+	ACL        types.ObjectCannedACL
+
+	// PresignOptions can replace the presigner or add client options. Its
+	// Expires is ignored in favor of Duration.
 	*s3.PresignOptions
+
+	// PutObjectInput sets other request parameters, such as ContentType,
+	// ContentMD5, a checksum or Metadata. Each one set is signed, so the
+	// upload has to send it with the same value. The SDK only signs
+	// ContentType when ContentLength is set too.
+	*s3.PutObjectInput
 }
 
-func (b *Bucket) PresignPut(input *PresignPutInput) (*v4.PresignedHTTPRequest, error) {
+// PresignPut presigns a PUT request that uploads an object.
+func (b *Bucket) PresignPut(input *PresignPutInput) (*PresignedRequest, error) {
+	return b.PresignPutContext(context.Background(), input)
+}
+
+func (b *Bucket) PresignPutContext(ctx context.Context, input *PresignPutInput) (*PresignedRequest, error) {
 	if b.Name == nil || *b.Name == "" {
-		return nil, fmt.Errorf("empty 'Name' param")
+		return nil, emptyParam("Name")
 	}
 	if input == nil {
-		return nil, fmt.Errorf("nil input")
+		return nil, nilInput()
 	}
 	if *input == (PresignPutInput{}) {
-		return nil, fmt.Errorf("empty input")
+		return nil, emptyInput()
 	}
 	if input.Key == nil || *input.Key == "" {
-		return nil, fmt.Errorf("empty 'Key' param")
+		return nil, emptyParam("Key")
+	}
+	if err := validatePresignDuration(input.Duration); err != nil {
+		return nil, err
 	}
-	if input.Duration == nil {
-		return nil, fmt.Errorf("empty 'Duration' param")
+
+	enc, err := b.encryption(input.Encryption)
+	if err != nil {
+		return nil, err
 	}
 
 	if b.Client == nil {
-		_, err := b.NewSession()
+		_, err := b.NewSessionContext(ctx)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	presignClient := s3.NewPresignClient(b.Client)
+	params := &s3.PutObjectInput{}
+	if input.PutObjectInput != nil {
+		*params = *input.PutObjectInput
+	}
+
+	params.Bucket = b.Name
+	params.Key = input.Key
+	if input.ACL != "" {
+		params.ACL = input.ACL
+	}
+	enc.applyPutObject(params)
 
-	out, err := presignClient.PresignPutObject(context.TODO(), &s3.PutObjectInput{
-		Bucket: b.Name,
-		Key:    input.Key,
-	}, s3.WithPresignExpires(*input.Duration))
+	expires := time.Now().Add(*input.Duration)
+
+	out, err := b.presignClient().PresignPutObject(ctx, params, presignOptions(input.PresignOptions, *input.Duration))
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign request: %w", apiError(err))
+	}
 
-	return out, err
+	return newPresignedRequest(out, expires), nil
 }
@@ -3,9 +3,11 @@ package s3_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"testing"
 	"time"
 
@@ -16,6 +18,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/itispx/goaws/s3"
+	"github.com/itispx/goaws/s3/s3test"
 )
 
 // import (
@@ -34,6 +37,38 @@ import (
 // 	"github.com/itispx/goaws/s3"
 // )
 
+// endpoint is the s3test server the tests run against, or empty when
+// GOAWS_LIVE is set and they run against AWS.
+var endpoint string
+
+func TestMain(m *testing.M) {
+	if os.Getenv("GOAWS_LIVE") != "" {
+		os.Exit(m.Run())
+	}
+
+	srv := s3test.NewServer()
+	endpoint = srv.URL
+
+	os.Setenv("AWS_ENDPOINT_URL_S3", srv.URL)
+	os.Setenv("AWS_ACCESS_KEY_ID", s3test.AccessKeyID)
+	os.Setenv("AWS_SECRET_ACCESS_KEY", s3test.SecretAccessKey)
+	os.Unsetenv("AWS_SESSION_TOKEN")
+
+	code := m.Run()
+
+	srv.Close()
+	os.Exit(code)
+}
+
+// objectURL is the URL goaws reports for key.
+func objectURL(bucket, region, key string) string {
+	if endpoint != "" {
+		return fmt.Sprintf("%s/%s/%s", endpoint, bucket, key)
+	}
+
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, region, key)
+}
+
 func getSVC(region string) (*awss3.Client, error) {
 	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
 	if err != nil {
@@ -575,7 +610,7 @@ func TestBucket_UploadObject(t *testing.T) {
 		t.Error(err.Error())
 	}
 
-	assertURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, region, key)
+	assertURL := objectURL(bucket, region, key)
 
 	if url != assertURL {
 		t.Error("incorrect path")
@@ -1316,3 +1351,86 @@ func TestBucket_PresignGetEmptyKey(t *testing.T) {
 // func TestBucket_PresignPut(t *testing.T) {
 // 	// trust
 // }
+
+func TestBucket_ContextDeadline(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	region := "us-east-1"
+	key := "key-name"
+	file := []byte("goaws")
+
+	tests := []struct {
+		name string
+		call func(ctx context.Context, bct *s3.Bucket) error
+	}{
+		{
+			name: "UploadObject",
+			call: func(ctx context.Context, bct *s3.Bucket) error {
+				_, _, err := bct.UploadObjectContext(ctx, &s3.BucketUploadObjectInput{
+					Key:  &key,
+					File: &file,
+				})
+				return err
+			},
+		},
+		{
+			name: "ListObjects",
+			call: func(ctx context.Context, bct *s3.Bucket) error {
+				_, err := bct.ListObjectsContext(ctx, nil)
+				return err
+			},
+		},
+		{
+			name: "UploadMultipart",
+			call: func(ctx context.Context, bct *s3.Bucket) error {
+				_, _, err := bct.UploadMultipartContext(ctx, &s3.BucketUploadMultipartInput{
+					Key:  &key,
+					Body: bytes.NewReader(file),
+				})
+				return err
+			},
+		},
+		{
+			name: "Download",
+			call: func(ctx context.Context, bct *s3.Bucket) error {
+				_, err := bct.DownloadContext(ctx, &s3.BucketDownloadInput{
+					Key:    &key,
+					Writer: s3.NewWriteAtBuffer(nil),
+				})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			httpClient := &recordingClient{}
+
+			bct := s3.Bucket{
+				Name:   &name,
+				Region: &region,
+			}
+
+			_, err := bct.NewSession(s3.WithStaticCredentials("AKID", "SECRET", ""), s3.WithHTTPClient(httpClient))
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+			defer cancel()
+
+			err = tt.call(ctx, &bct)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected a deadline error, got %v", err)
+			}
+
+			if httpClient.url != "" {
+				t.Errorf("expected no request, got one to '%s'", httpClient.url)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package s3test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+)
+
+// OwnerID is the canonical user ID that owns every bucket and object.
+const OwnerID = "s3test-owner"
+
+const (
+	allUsersGroup           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	authenticatedUsersGroup = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+	logDeliveryGroup        = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+)
+
+var errAccessControlListNotSupported = &apiError{http.StatusBadRequest, "AccessControlListNotSupported", "The bucket does not allow ACLs"}
+
+type groupGrant struct {
+	uri        string
+	permission string
+}
+
+var cannedGrants = map[string][]groupGrant{
+	"private":                   nil,
+	"bucket-owner-read":         nil,
+	"bucket-owner-full-control": nil,
+	"aws-exec-read":             nil,
+	"public-read":               {{allUsersGroup, "READ"}},
+	"public-read-write":         {{allUsersGroup, "READ"}, {allUsersGroup, "WRITE"}},
+	"authenticated-read":        {{authenticatedUsersGroup, "READ"}},
+	"log-delivery-write":        {{logDeliveryGroup, "WRITE"}, {logDeliveryGroup, "READ_ACP"}},
+}
+
+// cannedACL renders the AccessControlPolicy document for a canned ACL. The
+// owner always keeps FULL_CONTROL.
+func cannedACL(name string) ([]byte, bool) {
+	grants, ok := cannedGrants[name]
+	if !ok {
+		return nil, false
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<AccessControlPolicy xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`)
+	buf.WriteString(`<Owner><ID>` + OwnerID + `</ID></Owner><AccessControlList>`)
+	buf.WriteString(`<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="CanonicalUser">`)
+	buf.WriteString(`<ID>` + OwnerID + `</ID></Grantee><Permission>FULL_CONTROL</Permission></Grant>`)
+
+	for _, g := range grants {
+		buf.WriteString(`<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="Group"><URI>`)
+		xml.EscapeText(&buf, []byte(g.uri))
+		buf.WriteString(`</URI></Grantee><Permission>` + g.permission + `</Permission></Grant>`)
+	}
+
+	buf.WriteString(`</AccessControlList></AccessControlPolicy>`)
+
+	return buf.Bytes(), true
+}
+
+// aclEnforced reports whether the bucket has ACLs disabled through
+// BucketOwnerEnforced Object Ownership.
+func (b *bucket) aclEnforced() bool {
+	return bytes.Contains(b.configs["ownershipControls"], []byte("BucketOwnerEnforced"))
+}
+
+// checkACLHeader rejects a request's x-amz-acl header when the bucket does
+// not accept it.
+func checkACLHeader(b *bucket, h http.Header) *apiError {
+	canned := h.Get("X-Amz-Acl")
+	if canned == "" {
+		return nil
+	}
+	if _, ok := cannedGrants[canned]; !ok {
+		return errInvalidArgument("Invalid canned ACL '" + canned + "'")
+	}
+	if b.aclEnforced() && canned != "bucket-owner-full-control" {
+		return errAccessControlListNotSupported
+	}
+
+	return nil
+}
+
+// serveACL handles ?acl on buckets and objects. Unlike other configurations
+// an ACL always exists, defaulting to private, and may be set with either a
+// canned ACL header or a document.
+func serveACL(w http.ResponseWriter, r *http.Request, b *bucket, configs map[string][]byte) *apiError {
+	switch r.Method {
+	case http.MethodGet:
+		body, ok := configs["acl"]
+		if !ok {
+			body, _ = cannedACL("private")
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	case http.MethodPut:
+		if b.aclEnforced() {
+			return errAccessControlListNotSupported
+		}
+		if err := checkACLHeader(b, r.Header); err != nil {
+			return err
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return errInternal(err)
+		}
+
+		if canned := r.Header.Get("X-Amz-Acl"); canned != "" {
+			body, _ = cannedACL(canned)
+		} else if len(body) == 0 {
+			return errMalformedXML
+		}
+
+		configs["acl"] = body
+		w.WriteHeader(http.StatusOK)
+	default:
+		return errMethodNotAllowed
+	}
+
+	return nil
+}
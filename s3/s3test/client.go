@@ -0,0 +1,36 @@
+package s3test
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/google/uuid"
+
+	"github.com/itispx/goaws/s3"
+)
+
+// Client returns an SDK client that sends every request to the server.
+func (s *Server) Client() *awss3.Client {
+	return awss3.New(awss3.Options{
+		Region:       Region,
+		BaseEndpoint: aws.String(s.URL),
+		UsePathStyle: true,
+		Credentials:  credentials.NewStaticCredentialsProvider(AccessKeyID, SecretAccessKey, ""),
+	})
+}
+
+// Bucket creates a bucket with a random name on the server and returns a
+// goaws Bucket bound to it.
+func (s *Server) Bucket() *s3.Bucket {
+	name := uuid.New().String()
+
+	s.mu.Lock()
+	s.buckets[name] = newBucket()
+	s.mu.Unlock()
+
+	return &s3.Bucket{
+		Name:   aws.String(name),
+		Region: aws.String(Region),
+		Client: s.Client(),
+	}
+}
@@ -0,0 +1,89 @@
+package s3test
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+var (
+	errAccessDenied            = &apiError{http.StatusForbidden, "AccessDenied", "Access Denied"}
+	errBucketAlreadyOwnedByYou = &apiError{http.StatusConflict, "BucketAlreadyOwnedByYou", "Your previous request to create the named bucket succeeded and you already own it."}
+	errBucketNotEmpty          = &apiError{http.StatusConflict, "BucketNotEmpty", "The bucket you tried to delete is not empty"}
+	errEntityTooLarge          = &apiError{http.StatusBadRequest, "EntityTooLarge", "Your proposed upload exceeds the maximum allowed size"}
+	errEntityTooSmall          = &apiError{http.StatusBadRequest, "EntityTooSmall", "Your proposed upload is smaller than the minimum allowed object size."}
+	errInvalidPart             = &apiError{http.StatusBadRequest, "InvalidPart", "One or more of the specified parts could not be found."}
+	errInvalidPartOrder        = &apiError{http.StatusBadRequest, "InvalidPartOrder", "The list of parts was not in ascending order."}
+	errInvalidPartNumber       = &apiError{http.StatusRequestedRangeNotSatisfiable, "InvalidPartNumber", "The requested partnumber is not satisfiable"}
+	errInvalidRange            = &apiError{http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "The requested range is not satisfiable"}
+	errMalformedXML            = &apiError{http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema"}
+	errMethodNotAllowed        = &apiError{http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed against this resource."}
+	errNoSuchBucket            = &apiError{http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist"}
+	errNoSuchKey               = &apiError{http.StatusNotFound, "NoSuchKey", "The specified key does not exist."}
+	errNoSuchUpload            = &apiError{http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist."}
+	errNoSuchVersion           = &apiError{http.StatusNotFound, "NoSuchVersion", "The specified version does not exist."}
+	errPreconditionFailed      = &apiError{http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold"}
+	errSignatureDoesNotMatch   = &apiError{http.StatusForbidden, "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided."}
+)
+
+var configErrorCodes = map[string]string{
+	"cors":              "NoSuchCORSConfiguration",
+	"encryption":        "ServerSideEncryptionConfigurationNotFoundError",
+	"lifecycle":         "NoSuchLifecycleConfiguration",
+	"ownershipControls": "OwnershipControlsNotFoundError",
+	"policy":            "NoSuchBucketPolicy",
+	"publicAccessBlock": "NoSuchPublicAccessBlockConfiguration",
+	"tagging":           "NoSuchTagSet",
+}
+
+func errNoSuchConfiguration(name string) *apiError {
+	code, ok := configErrorCodes[name]
+	if !ok {
+		code = "NoSuchConfiguration"
+	}
+
+	return &apiError{http.StatusNotFound, code, "The specified configuration does not exist"}
+}
+
+func errInvalidArgument(message string) *apiError {
+	return &apiError{http.StatusBadRequest, "InvalidArgument", message}
+}
+
+func errInvalidRequest(message string) *apiError {
+	return &apiError{http.StatusBadRequest, "InvalidRequest", message}
+}
+
+func errInvalidPolicy(message string) *apiError {
+	return &apiError{http.StatusBadRequest, "InvalidPolicyDocument", message}
+}
+
+func errPolicyDenied(message string) *apiError {
+	return &apiError{http.StatusForbidden, "AccessDenied", message}
+}
+
+func errInternal(err error) *apiError {
+	return &apiError{http.StatusInternalServerError, "InternalError", err.Error()}
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err *apiError) {
+	if r.Method == http.MethodHead {
+		w.WriteHeader(err.status)
+		return
+	}
+
+	body, _ := xml.Marshal(errorResponse{
+		Code:     err.code,
+		Message:  err.message,
+		Resource: r.URL.Path,
+	})
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(err.status)
+	w.Write([]byte(xml.Header))
+	w.Write(body)
+}
@@ -0,0 +1,301 @@
+package s3test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/itispx/goaws/s3"
+)
+
+// Mock is an s3.API that records the calls it receives and answers them
+// with scripted responses instead of sending requests. The zero value is
+// ready to use.
+type Mock struct {
+	mu        sync.Mutex
+	calls     []Call
+	responses map[string][]func(input any) (any, error)
+}
+
+// Call is a request the mock received. Input is the operation's params, for
+// example a *s3.PutObjectInput.
+type Call struct {
+	Operation string
+	Input     any
+}
+
+var _ s3.API = (*Mock)(nil)
+
+// On queues a response for operation, named as in the SDK, for example
+// "PutObject". Each call takes the next queued response and the last one is
+// repeated. A nil output answers with an empty output of the right type.
+func (m *Mock) On(operation string, output any, err error) *Mock {
+	return m.OnFunc(operation, func(any) (any, error) {
+		return output, err
+	})
+}
+
+// OnFunc is like On but computes the response from the call's input.
+func (m *Mock) OnFunc(operation string, fn func(input any) (any, error)) *Mock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.responses == nil {
+		m.responses = map[string][]func(any) (any, error){}
+	}
+	m.responses[operation] = append(m.responses[operation], fn)
+
+	return m
+}
+
+// Calls returns the calls received so far, in order.
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the calls to operation received so far, in order.
+func (m *Mock) CallsTo(operation string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var calls []Call
+	for _, c := range m.calls {
+		if c.Operation == operation {
+			calls = append(calls, c)
+		}
+	}
+
+	return calls
+}
+
+// Options returns the region and credentials goaws presigns requests and
+// builds object URLs with.
+func (m *Mock) Options() awss3.Options {
+	return awss3.Options{
+		Region:      Region,
+		Credentials: credentials.NewStaticCredentialsProvider(AccessKeyID, SecretAccessKey, ""),
+	}
+}
+
+func (m *Mock) respond(operation string, input any) (any, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Operation: operation, Input: input})
+
+	queue := m.responses[operation]
+	if len(queue) == 0 {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("s3test: unexpected call to %s", operation)
+	}
+
+	fn := queue[0]
+	if len(queue) > 1 {
+		m.responses[operation] = queue[1:]
+	}
+	m.mu.Unlock()
+
+	return fn(input)
+}
+
+func call[T any](m *Mock, operation string, input any) (*T, error) {
+	output, err := m.respond(operation, input)
+	if output == nil {
+		if err != nil {
+			return nil, err
+		}
+
+		return new(T), nil
+	}
+
+	out, ok := output.(*T)
+	if !ok {
+		return nil, fmt.Errorf("s3test: %s response is %T, not %T", operation, output, out)
+	}
+
+	return out, err
+}
+
+func (m *Mock) AbortMultipartUpload(ctx context.Context, params *awss3.AbortMultipartUploadInput, optFns ...func(*awss3.Options)) (*awss3.AbortMultipartUploadOutput, error) {
+	return call[awss3.AbortMultipartUploadOutput](m, "AbortMultipartUpload", params)
+}
+
+func (m *Mock) CompleteMultipartUpload(ctx context.Context, params *awss3.CompleteMultipartUploadInput, optFns ...func(*awss3.Options)) (*awss3.CompleteMultipartUploadOutput, error) {
+	return call[awss3.CompleteMultipartUploadOutput](m, "CompleteMultipartUpload", params)
+}
+
+func (m *Mock) CopyObject(ctx context.Context, params *awss3.CopyObjectInput, optFns ...func(*awss3.Options)) (*awss3.CopyObjectOutput, error) {
+	return call[awss3.CopyObjectOutput](m, "CopyObject", params)
+}
+
+func (m *Mock) CreateBucket(ctx context.Context, params *awss3.CreateBucketInput, optFns ...func(*awss3.Options)) (*awss3.CreateBucketOutput, error) {
+	return call[awss3.CreateBucketOutput](m, "CreateBucket", params)
+}
+
+func (m *Mock) CreateMultipartUpload(ctx context.Context, params *awss3.CreateMultipartUploadInput, optFns ...func(*awss3.Options)) (*awss3.CreateMultipartUploadOutput, error) {
+	return call[awss3.CreateMultipartUploadOutput](m, "CreateMultipartUpload", params)
+}
+
+func (m *Mock) DeleteBucket(ctx context.Context, params *awss3.DeleteBucketInput, optFns ...func(*awss3.Options)) (*awss3.DeleteBucketOutput, error) {
+	return call[awss3.DeleteBucketOutput](m, "DeleteBucket", params)
+}
+
+func (m *Mock) DeleteBucketCors(ctx context.Context, params *awss3.DeleteBucketCorsInput, optFns ...func(*awss3.Options)) (*awss3.DeleteBucketCorsOutput, error) {
+	return call[awss3.DeleteBucketCorsOutput](m, "DeleteBucketCors", params)
+}
+
+func (m *Mock) DeleteBucketLifecycle(ctx context.Context, params *awss3.DeleteBucketLifecycleInput, optFns ...func(*awss3.Options)) (*awss3.DeleteBucketLifecycleOutput, error) {
+	return call[awss3.DeleteBucketLifecycleOutput](m, "DeleteBucketLifecycle", params)
+}
+
+func (m *Mock) DeleteBucketPolicy(ctx context.Context, params *awss3.DeleteBucketPolicyInput, optFns ...func(*awss3.Options)) (*awss3.DeleteBucketPolicyOutput, error) {
+	return call[awss3.DeleteBucketPolicyOutput](m, "DeleteBucketPolicy", params)
+}
+
+func (m *Mock) DeleteObject(ctx context.Context, params *awss3.DeleteObjectInput, optFns ...func(*awss3.Options)) (*awss3.DeleteObjectOutput, error) {
+	return call[awss3.DeleteObjectOutput](m, "DeleteObject", params)
+}
+
+func (m *Mock) DeleteObjects(ctx context.Context, params *awss3.DeleteObjectsInput, optFns ...func(*awss3.Options)) (*awss3.DeleteObjectsOutput, error) {
+	return call[awss3.DeleteObjectsOutput](m, "DeleteObjects", params)
+}
+
+func (m *Mock) DeletePublicAccessBlock(ctx context.Context, params *awss3.DeletePublicAccessBlockInput, optFns ...func(*awss3.Options)) (*awss3.DeletePublicAccessBlockOutput, error) {
+	return call[awss3.DeletePublicAccessBlockOutput](m, "DeletePublicAccessBlock", params)
+}
+
+func (m *Mock) GetBucketAccelerateConfiguration(ctx context.Context, params *awss3.GetBucketAccelerateConfigurationInput, optFns ...func(*awss3.Options)) (*awss3.GetBucketAccelerateConfigurationOutput, error) {
+	return call[awss3.GetBucketAccelerateConfigurationOutput](m, "GetBucketAccelerateConfiguration", params)
+}
+
+func (m *Mock) GetBucketAcl(ctx context.Context, params *awss3.GetBucketAclInput, optFns ...func(*awss3.Options)) (*awss3.GetBucketAclOutput, error) {
+	return call[awss3.GetBucketAclOutput](m, "GetBucketAcl", params)
+}
+
+func (m *Mock) GetBucketCors(ctx context.Context, params *awss3.GetBucketCorsInput, optFns ...func(*awss3.Options)) (*awss3.GetBucketCorsOutput, error) {
+	return call[awss3.GetBucketCorsOutput](m, "GetBucketCors", params)
+}
+
+func (m *Mock) GetBucketLifecycleConfiguration(ctx context.Context, params *awss3.GetBucketLifecycleConfigurationInput, optFns ...func(*awss3.Options)) (*awss3.GetBucketLifecycleConfigurationOutput, error) {
+	return call[awss3.GetBucketLifecycleConfigurationOutput](m, "GetBucketLifecycleConfiguration", params)
+}
+
+func (m *Mock) GetBucketLogging(ctx context.Context, params *awss3.GetBucketLoggingInput, optFns ...func(*awss3.Options)) (*awss3.GetBucketLoggingOutput, error) {
+	return call[awss3.GetBucketLoggingOutput](m, "GetBucketLogging", params)
+}
+
+func (m *Mock) GetBucketNotificationConfiguration(ctx context.Context, params *awss3.GetBucketNotificationConfigurationInput, optFns ...func(*awss3.Options)) (*awss3.GetBucketNotificationConfigurationOutput, error) {
+	return call[awss3.GetBucketNotificationConfigurationOutput](m, "GetBucketNotificationConfiguration", params)
+}
+
+func (m *Mock) GetBucketOwnershipControls(ctx context.Context, params *awss3.GetBucketOwnershipControlsInput, optFns ...func(*awss3.Options)) (*awss3.GetBucketOwnershipControlsOutput, error) {
+	return call[awss3.GetBucketOwnershipControlsOutput](m, "GetBucketOwnershipControls", params)
+}
+
+func (m *Mock) GetBucketPolicy(ctx context.Context, params *awss3.GetBucketPolicyInput, optFns ...func(*awss3.Options)) (*awss3.GetBucketPolicyOutput, error) {
+	return call[awss3.GetBucketPolicyOutput](m, "GetBucketPolicy", params)
+}
+
+func (m *Mock) GetBucketVersioning(ctx context.Context, params *awss3.GetBucketVersioningInput, optFns ...func(*awss3.Options)) (*awss3.GetBucketVersioningOutput, error) {
+	return call[awss3.GetBucketVersioningOutput](m, "GetBucketVersioning", params)
+}
+
+func (m *Mock) GetObject(ctx context.Context, params *awss3.GetObjectInput, optFns ...func(*awss3.Options)) (*awss3.GetObjectOutput, error) {
+	return call[awss3.GetObjectOutput](m, "GetObject", params)
+}
+
+func (m *Mock) GetObjectAcl(ctx context.Context, params *awss3.GetObjectAclInput, optFns ...func(*awss3.Options)) (*awss3.GetObjectAclOutput, error) {
+	return call[awss3.GetObjectAclOutput](m, "GetObjectAcl", params)
+}
+
+func (m *Mock) GetObjectTagging(ctx context.Context, params *awss3.GetObjectTaggingInput, optFns ...func(*awss3.Options)) (*awss3.GetObjectTaggingOutput, error) {
+	return call[awss3.GetObjectTaggingOutput](m, "GetObjectTagging", params)
+}
+
+func (m *Mock) GetPublicAccessBlock(ctx context.Context, params *awss3.GetPublicAccessBlockInput, optFns ...func(*awss3.Options)) (*awss3.GetPublicAccessBlockOutput, error) {
+	return call[awss3.GetPublicAccessBlockOutput](m, "GetPublicAccessBlock", params)
+}
+
+func (m *Mock) HeadObject(ctx context.Context, params *awss3.HeadObjectInput, optFns ...func(*awss3.Options)) (*awss3.HeadObjectOutput, error) {
+	return call[awss3.HeadObjectOutput](m, "HeadObject", params)
+}
+
+func (m *Mock) ListBuckets(ctx context.Context, params *awss3.ListBucketsInput, optFns ...func(*awss3.Options)) (*awss3.ListBucketsOutput, error) {
+	return call[awss3.ListBucketsOutput](m, "ListBuckets", params)
+}
+
+func (m *Mock) ListMultipartUploads(ctx context.Context, params *awss3.ListMultipartUploadsInput, optFns ...func(*awss3.Options)) (*awss3.ListMultipartUploadsOutput, error) {
+	return call[awss3.ListMultipartUploadsOutput](m, "ListMultipartUploads", params)
+}
+
+func (m *Mock) ListObjectVersions(ctx context.Context, params *awss3.ListObjectVersionsInput, optFns ...func(*awss3.Options)) (*awss3.ListObjectVersionsOutput, error) {
+	return call[awss3.ListObjectVersionsOutput](m, "ListObjectVersions", params)
+}
+
+func (m *Mock) ListObjectsV2(ctx context.Context, params *awss3.ListObjectsV2Input, optFns ...func(*awss3.Options)) (*awss3.ListObjectsV2Output, error) {
+	return call[awss3.ListObjectsV2Output](m, "ListObjectsV2", params)
+}
+
+func (m *Mock) ListParts(ctx context.Context, params *awss3.ListPartsInput, optFns ...func(*awss3.Options)) (*awss3.ListPartsOutput, error) {
+	return call[awss3.ListPartsOutput](m, "ListParts", params)
+}
+
+func (m *Mock) PutBucketAccelerateConfiguration(ctx context.Context, params *awss3.PutBucketAccelerateConfigurationInput, optFns ...func(*awss3.Options)) (*awss3.PutBucketAccelerateConfigurationOutput, error) {
+	return call[awss3.PutBucketAccelerateConfigurationOutput](m, "PutBucketAccelerateConfiguration", params)
+}
+
+func (m *Mock) PutBucketAcl(ctx context.Context, params *awss3.PutBucketAclInput, optFns ...func(*awss3.Options)) (*awss3.PutBucketAclOutput, error) {
+	return call[awss3.PutBucketAclOutput](m, "PutBucketAcl", params)
+}
+
+func (m *Mock) PutBucketCors(ctx context.Context, params *awss3.PutBucketCorsInput, optFns ...func(*awss3.Options)) (*awss3.PutBucketCorsOutput, error) {
+	return call[awss3.PutBucketCorsOutput](m, "PutBucketCors", params)
+}
+
+func (m *Mock) PutBucketLifecycleConfiguration(ctx context.Context, params *awss3.PutBucketLifecycleConfigurationInput, optFns ...func(*awss3.Options)) (*awss3.PutBucketLifecycleConfigurationOutput, error) {
+	return call[awss3.PutBucketLifecycleConfigurationOutput](m, "PutBucketLifecycleConfiguration", params)
+}
+
+func (m *Mock) PutBucketLogging(ctx context.Context, params *awss3.PutBucketLoggingInput, optFns ...func(*awss3.Options)) (*awss3.PutBucketLoggingOutput, error) {
+	return call[awss3.PutBucketLoggingOutput](m, "PutBucketLogging", params)
+}
+
+func (m *Mock) PutBucketNotificationConfiguration(ctx context.Context, params *awss3.PutBucketNotificationConfigurationInput, optFns ...func(*awss3.Options)) (*awss3.PutBucketNotificationConfigurationOutput, error) {
+	return call[awss3.PutBucketNotificationConfigurationOutput](m, "PutBucketNotificationConfiguration", params)
+}
+
+func (m *Mock) PutBucketOwnershipControls(ctx context.Context, params *awss3.PutBucketOwnershipControlsInput, optFns ...func(*awss3.Options)) (*awss3.PutBucketOwnershipControlsOutput, error) {
+	return call[awss3.PutBucketOwnershipControlsOutput](m, "PutBucketOwnershipControls", params)
+}
+
+func (m *Mock) PutBucketPolicy(ctx context.Context, params *awss3.PutBucketPolicyInput, optFns ...func(*awss3.Options)) (*awss3.PutBucketPolicyOutput, error) {
+	return call[awss3.PutBucketPolicyOutput](m, "PutBucketPolicy", params)
+}
+
+func (m *Mock) PutBucketVersioning(ctx context.Context, params *awss3.PutBucketVersioningInput, optFns ...func(*awss3.Options)) (*awss3.PutBucketVersioningOutput, error) {
+	return call[awss3.PutBucketVersioningOutput](m, "PutBucketVersioning", params)
+}
+
+func (m *Mock) PutObject(ctx context.Context, params *awss3.PutObjectInput, optFns ...func(*awss3.Options)) (*awss3.PutObjectOutput, error) {
+	return call[awss3.PutObjectOutput](m, "PutObject", params)
+}
+
+func (m *Mock) PutObjectAcl(ctx context.Context, params *awss3.PutObjectAclInput, optFns ...func(*awss3.Options)) (*awss3.PutObjectAclOutput, error) {
+	return call[awss3.PutObjectAclOutput](m, "PutObjectAcl", params)
+}
+
+func (m *Mock) PutPublicAccessBlock(ctx context.Context, params *awss3.PutPublicAccessBlockInput, optFns ...func(*awss3.Options)) (*awss3.PutPublicAccessBlockOutput, error) {
+	return call[awss3.PutPublicAccessBlockOutput](m, "PutPublicAccessBlock", params)
+}
+
+func (m *Mock) UploadPart(ctx context.Context, params *awss3.UploadPartInput, optFns ...func(*awss3.Options)) (*awss3.UploadPartOutput, error) {
+	return call[awss3.UploadPartOutput](m, "UploadPart", params)
+}
+
+func (m *Mock) UploadPartCopy(ctx context.Context, params *awss3.UploadPartCopyInput, optFns ...func(*awss3.Options)) (*awss3.UploadPartCopyOutput, error) {
+	return call[awss3.UploadPartCopyOutput](m, "UploadPartCopy", params)
+}
@@ -0,0 +1,96 @@
+package s3test_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+
+	"github.com/itispx/goaws/s3"
+	"github.com/itispx/goaws/s3/s3test"
+)
+
+func TestMock(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	mock := &s3test.Mock{}
+	mock.On("PutObject", &awss3.PutObjectOutput{ETag: aws.String(`"etag"`)}, nil)
+	mock.On("GetObject", nil, &smithy.GenericAPIError{Code: "AccessDenied"})
+	mock.On("GetObject", nil, &smithy.GenericAPIError{Code: "NoSuchKey"})
+
+	bct := s3.Bucket{
+		Name:   &name,
+		Client: mock,
+	}
+
+	out, _, err := bct.UploadObject(&s3.BucketUploadObjectInput{
+		Key:  aws.String("key-name"),
+		File: &[]byte{'g', 'o', 'a', 'w', 's'},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if aws.ToString(out.ETag) != `"etag"` {
+		t.Errorf("expected the scripted output, got %+v", out)
+	}
+
+	calls := mock.CallsTo("PutObject")
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 PutObject call, got %d", len(calls))
+	}
+
+	input := calls[0].Input.(*awss3.PutObjectInput)
+	if *input.Bucket != name || *input.Key != "key-name" {
+		t.Errorf("unexpected input %+v", input)
+	}
+
+	for _, want := range []error{s3.ErrAccessDenied, s3.ErrNotFound, s3.ErrNotFound} {
+		_, err = bct.GetObject(&s3.BucketGetObjectInput{
+			Key: aws.String("key-name"),
+		})
+		if !errors.Is(err, want) {
+			t.Errorf("expected %v, got %v", want, err)
+		}
+	}
+
+	_, err = bct.DeleteObject(&s3.BucketDeleteObjectInput{
+		Key: aws.String("key-name"),
+	})
+	if err == nil || !strings.Contains(err.Error(), "s3test: unexpected call to DeleteObject") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if n := len(mock.Calls()); n != 5 {
+		t.Errorf("expected 5 calls, got %d", n)
+	}
+}
+
+func TestMock_Presign(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	duration := time.Minute
+
+	bct := s3.Bucket{
+		Name:   &name,
+		Client: &s3test.Mock{},
+	}
+
+	req, err := bct.PresignGet(&s3.PresignGetInput{
+		Key:      aws.String("key-name"),
+		Duration: &duration,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !strings.HasPrefix(req.URL, "https://bucket-name.s3.us-east-1.amazonaws.com/key-name?") {
+		t.Errorf("unexpected URL '%s'", req.URL)
+	}
+}
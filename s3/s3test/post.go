@@ -0,0 +1,243 @@
+package s3test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type postResponse struct {
+	XMLName  xml.Name `xml:"PostResponse"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
+
+type postPolicy struct {
+	Expiration string            `json:"expiration"`
+	Conditions []json.RawMessage `json:"conditions"`
+}
+
+// postObject handles a browser form upload. Like S3 it checks the policy's
+// signature and expiry, that every condition holds and that every field is
+// covered by a condition.
+func postObject(w http.ResponseWriter, r *http.Request, name string, b *bucket) *apiError {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return errInvalidArgument("POST requires exactly one file upload per request.")
+	}
+
+	// Field names are case-insensitive.
+	fields := map[string]string{}
+	for k, v := range r.MultipartForm.Value {
+		fields[strings.ToLower(k)] = v[0]
+	}
+
+	files := r.MultipartForm.File["file"]
+	if len(files) != 1 {
+		return errInvalidArgument("POST requires exactly one file upload per request.")
+	}
+
+	f, err := files[0].Open()
+	if err != nil {
+		return errInternal(err)
+	}
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		return errInternal(err)
+	}
+
+	if apiErr := verifyPostSignature(fields); apiErr != nil {
+		return apiErr
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(fields["policy"])
+	if err != nil {
+		return errInvalidPolicy("Invalid Policy: Invalid JSON.")
+	}
+
+	var policy postPolicy
+	if err := json.Unmarshal(raw, &policy); err != nil {
+		return errInvalidPolicy("Invalid Policy: Invalid JSON.")
+	}
+
+	expiration, err := time.Parse(time.RFC3339, policy.Expiration)
+	if err != nil {
+		return errInvalidPolicy("Invalid Policy: Invalid 'expiration' value: '" + policy.Expiration + "'")
+	}
+	if time.Now().After(expiration) {
+		return errPolicyDenied("Invalid according to Policy: Policy expired.")
+	}
+
+	values := map[string]string{"bucket": name}
+	for k, v := range fields {
+		values[k] = v
+	}
+
+	covered := map[string]bool{"bucket": true}
+	for _, c := range policy.Conditions {
+		if apiErr := checkPostCondition(c, values, int64(len(data)), covered); apiErr != nil {
+			return apiErr
+		}
+	}
+
+	for k := range fields {
+		switch {
+		case covered[k], k == "policy", k == "x-amz-signature", strings.HasPrefix(k, "x-ignore-"):
+		default:
+			return errPolicyDenied("Invalid according to Policy: Extra input fields: " + k)
+		}
+	}
+
+	key := strings.ReplaceAll(fields["key"], "${filename}", files[0].Filename)
+	if key == "" {
+		return errInvalidArgument("Bucket POST must contain a field named 'key'.")
+	}
+
+	h := http.Header{}
+	for k, v := range fields {
+		h.Set(k, v)
+	}
+	if err := checkACLHeader(b, h); err != nil {
+		return err
+	}
+
+	o := &object{
+		data:     data,
+		etag:     etag(data),
+		modified: time.Now().UTC(),
+		header:   objectHeader(h),
+		configs:  requestConfigs(h),
+	}
+
+	b.put(key, o)
+
+	writeObjectHeader(w, b, o)
+
+	switch fields["success_action_status"] {
+	case "200":
+		w.WriteHeader(http.StatusOK)
+	case "201":
+		writeXML(w, http.StatusCreated, postResponse{
+			Location: "/" + name + "/" + key,
+			Bucket:   name,
+			Key:      key,
+			ETag:     o.etag,
+		})
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	return nil
+}
+
+func verifyPostSignature(fields map[string]string) *apiError {
+	if fields["policy"] == "" || fields["x-amz-signature"] == "" {
+		return errAccessDenied
+	}
+	if fields["x-amz-algorithm"] != "AWS4-HMAC-SHA256" {
+		return errInvalidArgument("Unsupported x-amz-algorithm: " + fields["x-amz-algorithm"])
+	}
+
+	credential := strings.Split(fields["x-amz-credential"], "/")
+	if len(credential) != 5 || credential[0] != AccessKeyID {
+		return &apiError{http.StatusForbidden, "InvalidAccessKeyId", "The AWS Access Key Id you provided does not exist in our records."}
+	}
+
+	key := hmacSHA256([]byte("AWS4"+SecretAccessKey), credential[1])
+	key = hmacSHA256(key, credential[2])
+	key = hmacSHA256(key, credential[3])
+	key = hmacSHA256(key, credential[4])
+
+	want := hex.EncodeToString(hmacSHA256(key, fields["policy"]))
+	if !hmac.Equal([]byte(want), []byte(fields["x-amz-signature"])) {
+		return errSignatureDoesNotMatch
+	}
+
+	return nil
+}
+
+// checkPostCondition checks one policy condition: {"field": "value"},
+// ["eq" | "starts-with", "$field", "value"] or
+// ["content-length-range", min, max]. It marks the field as covered.
+func checkPostCondition(raw json.RawMessage, values map[string]string, size int64, covered map[string]bool) *apiError {
+	var exact map[string]string
+	if json.Unmarshal(raw, &exact) == nil {
+		for k, v := range exact {
+			k = strings.ToLower(k)
+			covered[k] = true
+
+			if values[k] != v {
+				return errPolicyDenied(fmt.Sprintf("Invalid according to Policy: Policy Condition failed: [\"eq\", \"$%s\", \"%s\"]", k, v))
+			}
+		}
+
+		return nil
+	}
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err != nil || len(list) != 3 {
+		return errInvalidPolicy("Invalid Policy: Invalid Condition: " + string(raw))
+	}
+
+	var op string
+	if err := json.Unmarshal(list[0], &op); err != nil {
+		return errInvalidPolicy("Invalid Policy: Invalid Condition: " + string(raw))
+	}
+
+	if op == "content-length-range" {
+		var lo, hi int64
+		if json.Unmarshal(list[1], &lo) != nil || json.Unmarshal(list[2], &hi) != nil {
+			return errInvalidPolicy("Invalid Policy: Invalid content-length-range: " + string(raw))
+		}
+
+		if size < lo {
+			return errEntityTooSmall
+		}
+		if size > hi {
+			return errEntityTooLarge
+		}
+
+		return nil
+	}
+
+	var field, want string
+	if json.Unmarshal(list[1], &field) != nil || json.Unmarshal(list[2], &want) != nil {
+		return errInvalidPolicy("Invalid Policy: Invalid Condition: " + string(raw))
+	}
+
+	field = strings.ToLower(strings.TrimPrefix(field, "$"))
+	covered[field] = true
+
+	var ok bool
+	switch op {
+	case "eq":
+		ok = values[field] == want
+	case "starts-with":
+		ok = strings.HasPrefix(values[field], want)
+	default:
+		return errInvalidPolicy("Invalid Policy: Invalid Condition: " + string(raw))
+	}
+
+	if !ok {
+		return errPolicyDenied(fmt.Sprintf("Invalid according to Policy: Policy Condition failed: [\"%s\", \"$%s\", \"%s\"]", op, field, want))
+	}
+
+	return nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
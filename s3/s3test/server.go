@@ -0,0 +1,1152 @@
+// Package s3test provides an in-memory S3 server for tests that should not
+// need AWS credentials or a network.
+package s3test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Client signs with these credentials and presigned URLs are verified
+// against them.
+const (
+	Region          = "us-east-1"
+	AccessKeyID     = "AKIAS3TESTEXAMPLE"
+	SecretAccessKey = "s3test/secret/access/key/EXAMPLE"
+)
+
+// Server is an in-memory, S3-compatible HTTP server. It understands the
+// path-style requests the SDK sends for the operations goaws uses and keeps
+// all state in memory, so it is safe to create one per test.
+type Server struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	uploads map[string]*upload
+}
+
+type bucket struct {
+	created time.Time
+	objects map[string][]*object // versions of each key, oldest first
+	configs map[string][]byte
+}
+
+type object struct {
+	versionID    string
+	deleteMarker bool
+	data         []byte
+	etag         string
+	modified     time.Time
+	header       http.Header
+	configs      map[string][]byte
+	parts        []int64
+}
+
+type upload struct {
+	bucket    string
+	key       string
+	initiated time.Time
+	header    http.Header
+	configs   map[string][]byte
+	parts     map[int32]*part
+}
+
+type part struct {
+	data     []byte
+	etag     string
+	modified time.Time
+}
+
+func newBucket() *bucket {
+	return &bucket{
+		created: time.Now().UTC(),
+		objects: map[string][]*object{},
+		configs: map[string][]byte{},
+	}
+}
+
+// NewServer starts a Server. Callers should Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		buckets: map[string]*bucket{},
+		uploads: map[string]*upload{},
+	}
+
+	s.Server = httptest.NewServer(s)
+
+	return s
+}
+
+// bucketConfigs are the bucket subresources whose configuration documents
+// are stored as sent and returned unchanged.
+var bucketConfigs = []string{
+	"accelerate",
+	"cors",
+	"encryption",
+	"lifecycle",
+	"logging",
+	"notification",
+	"ownershipControls",
+	"policy",
+	"publicAccessBlock",
+	"tagging",
+	"versioning",
+}
+
+var objectConfigs = []string{
+	"acl",
+	"tagging",
+}
+
+var storedHeaders = []string{
+	"Cache-Control",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Language",
+	"Content-Type",
+	"Expires",
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("X-Amz-Signature") {
+		if err := verifyPresigned(r); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	// The body is read and the response buffered outside the lock, so that a
+	// slow client cannot hold it.
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, r, errInternal(err))
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	rec := httptest.NewRecorder()
+	s.mu.Lock()
+	s.serve(rec, r)
+	s.mu.Unlock()
+
+	for k, v := range rec.Header() {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(rec.Code)
+	w.Write(rec.Body.Bytes())
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	bucketName, key := splitPath(r.URL)
+	q := r.URL.Query()
+
+	var err *apiError
+	switch {
+	case bucketName == "":
+		if r.Method != http.MethodGet {
+			err = errMethodNotAllowed
+			break
+		}
+		s.listBuckets(w)
+	case key == "":
+		err = s.serveBucket(w, r, bucketName, q)
+	default:
+		err = s.serveObject(w, r, bucketName, key, q)
+	}
+
+	if err != nil {
+		writeError(w, r, err)
+	}
+}
+
+func (s *Server) serveBucket(w http.ResponseWriter, r *http.Request, name string, q url.Values) *apiError {
+	if r.Method == http.MethodPut && len(q) == 0 {
+		return s.createBucket(w, name)
+	}
+
+	b, ok := s.buckets[name]
+	if !ok {
+		return errNoSuchBucket
+	}
+
+	if q.Has("versioning") && r.Method == http.MethodGet && b.configs["versioning"] == nil {
+		writeXML(w, http.StatusOK, versioningConfiguration{})
+		return nil
+	}
+	if q.Has("accelerate") && r.Method == http.MethodGet && b.configs["accelerate"] == nil {
+		writeXML(w, http.StatusOK, accelerateConfiguration{})
+		return nil
+	}
+	if q.Has("logging") && r.Method == http.MethodGet && b.configs["logging"] == nil {
+		writeXML(w, http.StatusOK, bucketLoggingStatus{})
+		return nil
+	}
+	if q.Has("notification") && r.Method == http.MethodGet && b.configs["notification"] == nil {
+		writeXML(w, http.StatusOK, notificationConfiguration{})
+		return nil
+	}
+
+	if q.Has("acl") {
+		return serveACL(w, r, b, b.configs)
+	}
+
+	for _, c := range bucketConfigs {
+		if q.Has(c) {
+			return serveConfig(w, r, b.configs, c)
+		}
+	}
+
+	switch r.Method {
+	case http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+		return nil
+	case http.MethodDelete:
+		return s.deleteBucket(w, name, b)
+	case http.MethodPost:
+		if q.Has("delete") {
+			return s.deleteObjects(w, r, b)
+		}
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			return postObject(w, r, name, b)
+		}
+	case http.MethodGet:
+		switch {
+		case q.Has("uploads"):
+			return s.listMultipartUploads(w, name, q)
+		case q.Has("versions"):
+			return listObjectVersions(w, name, b, q)
+		case q.Has("location"):
+			writeXML(w, http.StatusOK, locationConstraint{})
+			return nil
+		default:
+			return listObjectsV2(w, name, b, q)
+		}
+	}
+
+	return errMethodNotAllowed
+}
+
+func (s *Server) serveObject(w http.ResponseWriter, r *http.Request, bucketName, key string, q url.Values) *apiError {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return errNoSuchBucket
+	}
+
+	for _, c := range objectConfigs {
+		if q.Has(c) {
+			o, err := b.lookup(w, key, q.Get("versionId"))
+			if err != nil {
+				return err
+			}
+
+			if c == "acl" {
+				return serveACL(w, r, b, o.configs)
+			}
+
+			if _, ok := o.configs[c]; !ok && c == "tagging" && r.Method == http.MethodGet {
+				writeXML(w, http.StatusOK, tagging{})
+				return nil
+			}
+
+			return serveConfig(w, r, o.configs, c)
+		}
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		switch {
+		case q.Has("uploadId") && r.Header.Get("X-Amz-Copy-Source") != "":
+			return s.uploadPartCopy(w, r, q)
+		case q.Has("uploadId"):
+			return s.uploadPart(w, r, q)
+		case r.Header.Get("X-Amz-Copy-Source") != "":
+			return s.copyObject(w, r, b, key)
+		default:
+			return putObject(w, r, b, key)
+		}
+	case http.MethodGet, http.MethodHead:
+		if q.Has("uploadId") {
+			return s.listParts(w, q)
+		}
+
+		return getObject(w, r, b, key)
+	case http.MethodDelete:
+		if q.Has("uploadId") {
+			return s.abortMultipartUpload(w, q)
+		}
+
+		writeVersionHeader(w, b, b.remove(key, q.Get("versionId")))
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	case http.MethodPost:
+		switch {
+		case q.Has("uploads"):
+			return s.createMultipartUpload(w, r, bucketName, key)
+		case q.Has("uploadId"):
+			return s.completeMultipartUpload(w, r, b, q)
+		}
+	}
+
+	return errMethodNotAllowed
+}
+
+func serveConfig(w http.ResponseWriter, r *http.Request, configs map[string][]byte, name string) *apiError {
+	switch r.Method {
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return errInternal(err)
+		}
+
+		configs[name] = body
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		body, ok := configs[name]
+		if !ok {
+			return errNoSuchConfiguration(name)
+		}
+
+		if name == "policy" {
+			w.Header().Set("Content-Type", "application/json")
+		} else {
+			w.Header().Set("Content-Type", "application/xml")
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	case http.MethodDelete:
+		delete(configs, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		return errMethodNotAllowed
+	}
+
+	return nil
+}
+
+func (s *Server) listBuckets(w http.ResponseWriter) {
+	out := listAllMyBucketsResult{}
+	for name, b := range s.buckets {
+		out.Buckets = append(out.Buckets, bucketEntry{
+			Name:         name,
+			CreationDate: b.created.Format(time.RFC3339),
+		})
+	}
+
+	sort.Slice(out.Buckets, func(i, j int) bool {
+		return out.Buckets[i].Name < out.Buckets[j].Name
+	})
+
+	writeXML(w, http.StatusOK, out)
+}
+
+func (s *Server) createBucket(w http.ResponseWriter, name string) *apiError {
+	if _, ok := s.buckets[name]; ok {
+		return errBucketAlreadyOwnedByYou
+	}
+
+	s.buckets[name] = newBucket()
+
+	w.Header().Set("Location", "/"+name)
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+func (s *Server) deleteBucket(w http.ResponseWriter, name string, b *bucket) *apiError {
+	if len(b.objects) > 0 {
+		return errBucketNotEmpty
+	}
+
+	delete(s.buckets, name)
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+func putObject(w http.ResponseWriter, r *http.Request, b *bucket, key string) *apiError {
+	if err := checkACLHeader(b, r.Header); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return errInternal(err)
+	}
+
+	o := &object{
+		data:     data,
+		etag:     etag(data),
+		modified: time.Now().UTC(),
+		header:   objectHeader(r.Header),
+		configs:  requestConfigs(r.Header),
+	}
+
+	b.put(key, o)
+
+	writeObjectHeader(w, b, o)
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+func getObject(w http.ResponseWriter, r *http.Request, b *bucket, key string) *apiError {
+	o, apiErr := b.lookup(w, key, r.URL.Query().Get("versionId"))
+	if apiErr != nil {
+		return apiErr
+	}
+
+	if err := checkCustomerKey(r.Header, o.header); err != nil {
+		return err
+	}
+
+	if match := r.Header.Get("If-Match"); match != "" && match != o.etag {
+		return errPreconditionFailed
+	}
+	if match := r.Header.Get("If-None-Match"); match != "" && match == o.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	writeObjectHeader(w, b, o)
+
+	for k, v := range responseOverrides(r) {
+		w.Header().Set(k, v)
+	}
+	w.Header().Set("Accept-Ranges", "bytes")
+
+	data := o.data
+	status := http.StatusOK
+
+	if v := r.URL.Query().Get("partNumber"); v != "" {
+		number, err := strconv.Atoi(v)
+		if err != nil || number < 1 || (o.parts == nil && number > 1) || (o.parts != nil && number > len(o.parts)) {
+			return errInvalidPartNumber
+		}
+
+		if o.parts != nil {
+			var start int64
+			for _, size := range o.parts[:number-1] {
+				start += size
+			}
+
+			end := start + o.parts[number-1]
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, len(data)))
+			w.Header().Set("X-Amz-Mp-Parts-Count", strconv.Itoa(len(o.parts)))
+			data = data[start:end]
+			status = http.StatusPartialContent
+		}
+	} else if rng := r.Header.Get("Range"); rng != "" {
+		start, end, ok := parseRange(rng, int64(len(data)))
+		if !ok {
+			return errInvalidRange
+		}
+
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		data = data[start : end+1]
+		status = http.StatusPartialContent
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+
+	if r.Method != http.MethodHead {
+		w.Write(data)
+	}
+
+	return nil
+}
+
+var responseOverrideParams = map[string]string{
+	"response-cache-control":       "Cache-Control",
+	"response-content-disposition": "Content-Disposition",
+	"response-content-encoding":    "Content-Encoding",
+	"response-content-language":    "Content-Language",
+	"response-content-type":        "Content-Type",
+	"response-expires":             "Expires",
+}
+
+// responseOverrides returns the response headers a GET asked to override
+// through its query string.
+func responseOverrides(r *http.Request) map[string]string {
+	out := map[string]string{}
+	q := r.URL.Query()
+
+	for param, header := range responseOverrideParams {
+		if v := q.Get(param); v != "" {
+			out[header] = v
+		}
+	}
+
+	return out
+}
+
+func (s *Server) copyObject(w http.ResponseWriter, r *http.Request, b *bucket, key string) *apiError {
+	if err := checkACLHeader(b, r.Header); err != nil {
+		return err
+	}
+
+	src, err := s.copySource(r.Header)
+	if err != nil {
+		return err
+	}
+
+	header := src.header.Clone()
+	for k := range header {
+		if strings.HasPrefix(k, "X-Amz-Server-Side-Encryption") {
+			delete(header, k)
+		}
+	}
+
+	if strings.EqualFold(r.Header.Get("X-Amz-Metadata-Directive"), "REPLACE") {
+		header = objectHeader(r.Header)
+	} else {
+		for k, v := range objectHeader(r.Header) {
+			if !strings.HasPrefix(k, "X-Amz-Meta-") {
+				header[k] = v
+			}
+		}
+	}
+
+	configs := requestConfigs(r.Header)
+	if !strings.EqualFold(r.Header.Get("X-Amz-Tagging-Directive"), "REPLACE") {
+		delete(configs, "tagging")
+		if t, ok := src.configs["tagging"]; ok {
+			configs["tagging"] = t
+		}
+	}
+
+	o := &object{
+		data:     append([]byte(nil), src.data...),
+		etag:     src.etag,
+		modified: time.Now().UTC(),
+		header:   header,
+		configs:  configs,
+	}
+
+	b.put(key, o)
+	writeVersionHeader(w, b, o)
+
+	writeXML(w, http.StatusOK, copyObjectResult{
+		ETag:         o.etag,
+		LastModified: o.modified.Format(time.RFC3339),
+	})
+
+	return nil
+}
+
+func (s *Server) copySource(h http.Header) (*object, *apiError) {
+	source, err := url.PathUnescape(strings.TrimPrefix(h.Get("X-Amz-Copy-Source"), "/"))
+	if err != nil {
+		return nil, errInvalidArgument("invalid copy source")
+	}
+
+	source, versionID, _ := strings.Cut(source, "?versionId=")
+
+	bucketName, key, ok := strings.Cut(source, "/")
+	if !ok {
+		return nil, errInvalidArgument("invalid copy source")
+	}
+
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return nil, errNoSuchBucket
+	}
+
+	o, apiErr := b.lookup(httptest.NewRecorder(), key, versionID)
+	if apiErr != nil {
+		if apiErr == errMethodNotAllowed {
+			return nil, errInvalidRequest("the source of a copy request may not specifically refer to a delete marker by version id")
+		}
+
+		return nil, apiErr
+	}
+
+	if match := h.Get("X-Amz-Copy-Source-If-Match"); match != "" && match != o.etag {
+		return nil, errPreconditionFailed
+	}
+
+	sourceKey := http.Header{}
+	sourceKey.Set("X-Amz-Server-Side-Encryption-Customer-Key-Md5", h.Get("X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key-Md5"))
+	if err := checkCustomerKey(sourceKey, o.header); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func (s *Server) deleteObjects(w http.ResponseWriter, r *http.Request, b *bucket) *apiError {
+	var in deleteRequest
+	if err := xml.NewDecoder(r.Body).Decode(&in); err != nil {
+		return errMalformedXML
+	}
+	if len(in.Objects) > 1000 {
+		return errMalformedXML
+	}
+
+	out := deleteResult{}
+	for _, o := range in.Objects {
+		entry := deletedEntry{
+			Key:       o.Key,
+			VersionID: o.VersionID,
+		}
+
+		removed := b.remove(o.Key, o.VersionID)
+		if removed != nil && removed.deleteMarker {
+			entry.DeleteMarker = true
+			if o.VersionID == "" {
+				entry.DeleteMarkerVersionID = removed.versionID
+			}
+		}
+
+		if !in.Quiet {
+			out.Deleted = append(out.Deleted, entry)
+		}
+	}
+
+	writeXML(w, http.StatusOK, out)
+
+	return nil
+}
+
+func listObjectsV2(w http.ResponseWriter, name string, b *bucket, q url.Values) *apiError {
+	prefix := q.Get("prefix")
+	delimiter := q.Get("delimiter")
+
+	maxKeys := 1000
+	if v := q.Get("max-keys"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return errInvalidArgument("invalid max-keys")
+		}
+		if n < maxKeys {
+			maxKeys = n
+		}
+	}
+
+	after := q.Get("start-after")
+	if token := q.Get("continuation-token"); token != "" {
+		after = token
+	}
+
+	out := listBucketResult{
+		Name:              name,
+		Prefix:            prefix,
+		Delimiter:         delimiter,
+		MaxKeys:           maxKeys,
+		StartAfter:        q.Get("start-after"),
+		ContinuationToken: q.Get("continuation-token"),
+	}
+
+	seen := map[string]bool{}
+	last := ""
+
+	for _, key := range b.currentKeys() {
+		if !strings.HasPrefix(key, prefix) || key <= after {
+			continue
+		}
+
+		entry := key
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				entry = key[:len(prefix)+i+len(delimiter)]
+			}
+		}
+
+		if seen[entry] {
+			continue
+		}
+
+		if out.KeyCount == maxKeys {
+			out.IsTruncated = true
+			out.NextContinuationToken = last
+			break
+		}
+
+		seen[entry] = true
+		out.KeyCount++
+
+		if entry != key {
+			out.CommonPrefixes = append(out.CommonPrefixes, commonPrefix{Prefix: entry})
+			// Skip everything else under the common prefix.
+			last = entry + "\U0010FFFF"
+			continue
+		}
+
+		o := b.current(key)
+		out.Contents = append(out.Contents, objectEntry{
+			Key:          key,
+			LastModified: o.modified.Format(time.RFC3339Nano),
+			ETag:         o.etag,
+			Size:         int64(len(o.data)),
+			StorageClass: storageClass(o.header),
+		})
+		last = key
+	}
+
+	writeXML(w, http.StatusOK, out)
+
+	return nil
+}
+
+func (s *Server) createMultipartUpload(w http.ResponseWriter, r *http.Request, bucketName, key string) *apiError {
+	if err := checkACLHeader(s.buckets[bucketName], r.Header); err != nil {
+		return err
+	}
+
+	id := uuid.New().String()
+
+	s.uploads[id] = &upload{
+		bucket:    bucketName,
+		key:       key,
+		initiated: time.Now().UTC(),
+		header:    objectHeader(r.Header),
+		configs:   requestConfigs(r.Header),
+		parts:     map[int32]*part{},
+	}
+
+	writeXML(w, http.StatusOK, initiateMultipartUploadResult{
+		Bucket:   bucketName,
+		Key:      key,
+		UploadID: id,
+	})
+
+	return nil
+}
+
+func (s *Server) multipartUpload(q url.Values) (*upload, int32, *apiError) {
+	u, ok := s.uploads[q.Get("uploadId")]
+	if !ok {
+		return nil, 0, errNoSuchUpload
+	}
+
+	if !q.Has("partNumber") {
+		return u, 0, nil
+	}
+
+	number, err := strconv.Atoi(q.Get("partNumber"))
+	if err != nil || number < 1 || number > 10000 {
+		return nil, 0, errInvalidArgument("invalid partNumber")
+	}
+
+	return u, int32(number), nil
+}
+
+func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, q url.Values) *apiError {
+	u, number, apiErr := s.multipartUpload(q)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	if err := checkCustomerKey(r.Header, u.header); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return errInternal(err)
+	}
+
+	p := &part{
+		data:     data,
+		etag:     etag(data),
+		modified: time.Now().UTC(),
+	}
+
+	u.parts[number] = p
+
+	w.Header().Set("ETag", p.etag)
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+func (s *Server) uploadPartCopy(w http.ResponseWriter, r *http.Request, q url.Values) *apiError {
+	u, number, apiErr := s.multipartUpload(q)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	src, apiErr := s.copySource(r.Header)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	data := src.data
+	if rng := r.Header.Get("X-Amz-Copy-Source-Range"); rng != "" {
+		start, end, ok := parseRange(rng, int64(len(data)))
+		if !ok {
+			return errInvalidRange
+		}
+
+		data = data[start : end+1]
+	}
+
+	p := &part{
+		data:     append([]byte(nil), data...),
+		etag:     etag(data),
+		modified: time.Now().UTC(),
+	}
+
+	u.parts[number] = p
+
+	writeXML(w, http.StatusOK, copyPartResult{
+		ETag:         p.etag,
+		LastModified: p.modified.Format(time.RFC3339),
+	})
+
+	return nil
+}
+
+func (s *Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request, b *bucket, q url.Values) *apiError {
+	u, _, apiErr := s.multipartUpload(q)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	var in completeMultipartUpload
+	if err := xml.NewDecoder(r.Body).Decode(&in); err != nil || len(in.Parts) == 0 {
+		return errMalformedXML
+	}
+
+	var (
+		data  []byte
+		sums  []byte
+		sizes []int64
+		prev  int32
+	)
+
+	for i, cp := range in.Parts {
+		if cp.PartNumber <= prev {
+			return errInvalidPartOrder
+		}
+		prev = cp.PartNumber
+
+		p, ok := u.parts[cp.PartNumber]
+		if !ok || p.etag != cp.ETag {
+			return errInvalidPart
+		}
+		if i < len(in.Parts)-1 && len(p.data) < 5*1024*1024 {
+			return errEntityTooSmall
+		}
+
+		sum, _ := hex.DecodeString(strings.Trim(p.etag, `"`))
+		sums = append(sums, sum...)
+		data = append(data, p.data...)
+		sizes = append(sizes, int64(len(p.data)))
+	}
+
+	sum := md5.Sum(sums)
+
+	o := &object{
+		data:     data,
+		etag:     fmt.Sprintf(`"%x-%d"`, sum, len(in.Parts)),
+		modified: time.Now().UTC(),
+		header:   u.header,
+		configs:  u.configs,
+		parts:    sizes,
+	}
+
+	b.put(u.key, o)
+	delete(s.uploads, q.Get("uploadId"))
+
+	writeVersionHeader(w, b, o)
+
+	writeXML(w, http.StatusOK, completeMultipartUploadResult{
+		Bucket: u.bucket,
+		Key:    u.key,
+		ETag:   o.etag,
+	})
+
+	return nil
+}
+
+func (s *Server) abortMultipartUpload(w http.ResponseWriter, q url.Values) *apiError {
+	if _, ok := s.uploads[q.Get("uploadId")]; !ok {
+		return errNoSuchUpload
+	}
+
+	delete(s.uploads, q.Get("uploadId"))
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+func (s *Server) listParts(w http.ResponseWriter, q url.Values) *apiError {
+	u, _, apiErr := s.multipartUpload(q)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	marker := 0
+	if v := q.Get("part-number-marker"); v != "" {
+		marker, _ = strconv.Atoi(v)
+	}
+
+	maxParts := 1000
+	if v := q.Get("max-parts"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n < maxParts {
+			maxParts = n
+		}
+	}
+
+	numbers := []int{}
+	for n := range u.parts {
+		if int(n) > marker {
+			numbers = append(numbers, int(n))
+		}
+	}
+	sort.Ints(numbers)
+
+	out := listPartsResult{
+		Bucket:           u.bucket,
+		Key:              u.key,
+		UploadID:         q.Get("uploadId"),
+		PartNumberMarker: marker,
+		MaxParts:         maxParts,
+	}
+
+	for i, n := range numbers {
+		if i == maxParts {
+			out.IsTruncated = true
+			break
+		}
+
+		p := u.parts[int32(n)]
+		out.Parts = append(out.Parts, partEntry{
+			PartNumber:   n,
+			ETag:         p.etag,
+			Size:         int64(len(p.data)),
+			LastModified: p.modified.Format(time.RFC3339Nano),
+		})
+		out.NextPartNumberMarker = n
+	}
+
+	writeXML(w, http.StatusOK, out)
+
+	return nil
+}
+
+func (s *Server) listMultipartUploads(w http.ResponseWriter, bucketName string, q url.Values) *apiError {
+	prefix := q.Get("prefix")
+
+	out := listMultipartUploadsResult{
+		Bucket:     bucketName,
+		Prefix:     prefix,
+		MaxUploads: 1000,
+	}
+
+	for id, u := range s.uploads {
+		if u.bucket != bucketName || !strings.HasPrefix(u.key, prefix) {
+			continue
+		}
+
+		out.Uploads = append(out.Uploads, uploadEntry{
+			Key:       u.key,
+			UploadID:  id,
+			Initiated: u.initiated.Format(time.RFC3339Nano),
+		})
+	}
+
+	sort.Slice(out.Uploads, func(i, j int) bool {
+		if out.Uploads[i].Key != out.Uploads[j].Key {
+			return out.Uploads[i].Key < out.Uploads[j].Key
+		}
+		return out.Uploads[i].UploadID < out.Uploads[j].UploadID
+	})
+
+	writeXML(w, http.StatusOK, out)
+
+	return nil
+}
+
+func splitPath(u *url.URL) (string, string) {
+	p := strings.TrimPrefix(u.EscapedPath(), "/")
+
+	bucketName, key, _ := strings.Cut(p, "/")
+
+	bucketName, _ = url.PathUnescape(bucketName)
+	key, _ = url.PathUnescape(key)
+
+	return bucketName, key
+}
+
+// requestConfigs returns the object configurations set by request headers:
+// tagging and a canned ACL.
+func requestConfigs(h http.Header) map[string][]byte {
+	configs := taggingConfig(h)
+
+	if acl, ok := cannedACL(h.Get("X-Amz-Acl")); ok {
+		configs["acl"] = acl
+	}
+
+	return configs
+}
+
+// taggingConfig turns an x-amz-tagging header into the document a
+// GetObjectTagging call returns.
+func taggingConfig(h http.Header) map[string][]byte {
+	configs := map[string][]byte{}
+
+	v := h.Get("X-Amz-Tagging")
+	if v == "" {
+		return configs
+	}
+
+	values, err := url.ParseQuery(v)
+	if err != nil {
+		return configs
+	}
+
+	t := tagging{}
+	for k := range values {
+		t.TagSet = append(t.TagSet, tag{Key: k, Value: values.Get(k)})
+	}
+
+	sort.Slice(t.TagSet, func(i, j int) bool {
+		return t.TagSet[i].Key < t.TagSet[j].Key
+	})
+
+	configs["tagging"], _ = xml.Marshal(t)
+
+	return configs
+}
+
+func objectHeader(h http.Header) http.Header {
+	out := http.Header{}
+
+	for _, k := range storedHeaders {
+		if v := h.Get(k); v != "" {
+			out.Set(k, v)
+		}
+	}
+
+	for k, v := range h {
+		k = http.CanonicalHeaderKey(k)
+
+		switch {
+		case strings.HasPrefix(k, "X-Amz-Meta-"),
+			k == "X-Amz-Storage-Class",
+			k == "X-Amz-Server-Side-Encryption",
+			k == "X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id",
+			k == "X-Amz-Server-Side-Encryption-Bucket-Key-Enabled",
+			k == "X-Amz-Server-Side-Encryption-Customer-Algorithm",
+			k == "X-Amz-Server-Side-Encryption-Customer-Key-Md5",
+			k == "X-Amz-Website-Redirect-Location":
+			out[k] = append([]string(nil), v...)
+		}
+	}
+
+	return out
+}
+
+func writeObjectHeader(w http.ResponseWriter, b *bucket, o *object) {
+	for k, v := range o.header {
+		w.Header()[k] = v
+	}
+
+	writeVersionHeader(w, b, o)
+
+	w.Header().Set("ETag", o.etag)
+	w.Header().Set("Last-Modified", o.modified.Format(http.TimeFormat))
+}
+
+// checkCustomerKey enforces SSE-C: an object written with a customer key
+// can only be read with the same key.
+func checkCustomerKey(req, stored http.Header) *apiError {
+	want := stored.Get("X-Amz-Server-Side-Encryption-Customer-Key-Md5")
+	if want == "" {
+		return nil
+	}
+
+	if req.Get("X-Amz-Server-Side-Encryption-Customer-Key-Md5") != want {
+		return errInvalidRequest("the object was stored using a form of server side encryption; the correct parameters must be provided to retrieve the object")
+	}
+
+	return nil
+}
+
+func storageClass(h http.Header) string {
+	if c := h.Get("X-Amz-Storage-Class"); c != "" {
+		return c
+	}
+
+	return "STANDARD"
+}
+
+func etag(data []byte) string {
+	return fmt.Sprintf(`"%x"`, md5.Sum(data))
+}
+
+// parseRange parses a single "bytes=start-end" range against an object of
+// the given size and returns the inclusive bounds.
+func parseRange(rng string, size int64) (int64, int64, bool) {
+	spec, ok := strings.CutPrefix(rng, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+
+	first, last, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, 0, false
+	}
+
+	if first == "" {
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 || size == 0 {
+			return 0, 0, false
+		}
+		if n > size {
+			n = size
+		}
+
+		return size - n, size - 1, true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start >= size {
+		return 0, 0, false
+	}
+
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+
+	return start, end, true
+}
+
+func writeXML(w http.ResponseWriter, status int, v any) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	if err := xml.NewEncoder(&buf).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,316 @@
+package s3test_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+
+	"github.com/itispx/goaws/s3"
+	"github.com/itispx/goaws/s3/s3test"
+)
+
+func newServer(t *testing.T) *s3test.Server {
+	srv := s3test.NewServer()
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func errorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+
+	return ""
+}
+
+func TestServer_Buckets(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t)
+	svc := srv.Client()
+	ctx := context.Background()
+
+	_, err := svc.CreateBucket(ctx, &awss3.CreateBucketInput{
+		Bucket: aws.String("bucket-name"),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = svc.CreateBucket(ctx, &awss3.CreateBucketInput{
+		Bucket: aws.String("bucket-name"),
+	})
+	if errorCode(err) != "BucketAlreadyOwnedByYou" {
+		t.Errorf("expected the bucket to exist, got %v", err)
+	}
+
+	list, err := svc.ListBuckets(ctx, &awss3.ListBucketsInput{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(list.Buckets) != 1 || *list.Buckets[0].Name != "bucket-name" {
+		t.Errorf("unexpected buckets %+v", list.Buckets)
+	}
+
+	_, err = svc.PutObject(ctx, &awss3.PutObjectInput{
+		Bucket: aws.String("bucket-name"),
+		Key:    aws.String("key-name"),
+		Body:   strings.NewReader("goaws"),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = svc.DeleteBucket(ctx, &awss3.DeleteBucketInput{
+		Bucket: aws.String("bucket-name"),
+	})
+	if errorCode(err) != "BucketNotEmpty" {
+		t.Errorf("expected the bucket not to be empty, got %v", err)
+	}
+
+	_, err = svc.DeleteObject(ctx, &awss3.DeleteObjectInput{
+		Bucket: aws.String("bucket-name"),
+		Key:    aws.String("key-name"),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = svc.DeleteBucket(ctx, &awss3.DeleteBucketInput{
+		Bucket: aws.String("bucket-name"),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = svc.HeadBucket(ctx, &awss3.HeadBucketInput{
+		Bucket: aws.String("bucket-name"),
+	})
+	if errorCode(err) != "NotFound" {
+		t.Errorf("expected the bucket to be gone, got %v", err)
+	}
+}
+
+func TestServer_Objects(t *testing.T) {
+	t.Parallel()
+
+	bct := newServer(t).Bucket()
+
+	for _, k := range []string{"a/1", "a/2", "b"} {
+		_, _, err := bct.UploadObject(&s3.BucketUploadObjectInput{
+			Key:  aws.String(k),
+			File: &[]byte{'g', 'o', 'a', 'w', 's'},
+		})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	list, err := bct.ListObjects(&s3.ListObjectsInput{
+		Prefix: aws.String("a/"),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(list.Contents) != 2 || *list.Contents[0].Key != "a/1" || *list.Contents[1].Key != "a/2" {
+		t.Errorf("unexpected objects %+v", list.Contents)
+	}
+
+	out, err := bct.GetObject(&s3.BucketGetObjectInput{
+		Key: aws.String("b"),
+		GetObjectInput: &awss3.GetObjectInput{
+			Key:   aws.String("b"),
+			Range: aws.String("bytes=1-3"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	data, _ := io.ReadAll(out.Body)
+	out.Body.Close()
+
+	if string(data) != "oaw" {
+		t.Errorf("expected 'oaw', got '%s'", data)
+	}
+
+	_, err = bct.DeleteObject(&s3.BucketDeleteObjectInput{
+		Key: aws.String("b"),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = bct.GetObject(&s3.BucketGetObjectInput{
+		Key: aws.String("b"),
+	})
+	if !errors.Is(err, s3.ErrNotFound) {
+		t.Errorf("expected the object to be gone, got %v", err)
+	}
+}
+
+func TestServer_MultipartAndCopy(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t)
+	bct := srv.Bucket()
+	dst := srv.Bucket()
+
+	data := bytes.Repeat([]byte("goaws"), int(s3.MinPartSize)/4)
+	concurrency := 2
+
+	_, _, err := bct.UploadMultipart(&s3.BucketUploadMultipartInput{
+		Body:        bytes.NewReader(data),
+		Key:         aws.String("key-name"),
+		Concurrency: &concurrency,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, _, err = bct.CopyObject(&s3.BucketCopyObjectInput{
+		Key:         aws.String("key-name"),
+		Destination: dst,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	out, err := dst.GetObject(&s3.BucketGetObjectInput{
+		Key: aws.String("key-name"),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	got, _ := io.ReadAll(out.Body)
+	out.Body.Close()
+
+	if !bytes.Equal(got, data) {
+		t.Errorf("expected %d bytes, got %d", len(data), len(got))
+	}
+}
+
+func TestServer_Presigned(t *testing.T) {
+	t.Parallel()
+
+	bct := newServer(t).Bucket()
+	duration := time.Minute
+
+	_, _, err := bct.UploadObject(&s3.BucketUploadObjectInput{
+		Key:  aws.String("key-name"),
+		File: &[]byte{'g', 'o', 'a', 'w', 's'},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	req, err := bct.PresignGet(&s3.PresignGetInput{
+		Key:      aws.String("key-name"),
+		Duration: &duration,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tests := []struct {
+		name   string
+		modify func(q url.Values)
+		status int
+	}{
+		{"Valid", func(q url.Values) {}, http.StatusOK},
+		{"WrongSignature", func(q url.Values) {
+			q.Set("X-Amz-Signature", strings.Repeat("0", 64))
+		}, http.StatusForbidden},
+		{"Expired", func(q url.Values) {
+			q.Set("X-Amz-Date", time.Now().Add(-time.Hour).UTC().Format("20060102T150405Z"))
+		}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			u, err := url.Parse(req.URL)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			q := u.Query()
+			tt.modify(q)
+			u.RawQuery = q.Encode()
+
+			resp, err := http.Get(u.String())
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestServer_SlowBody(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t)
+	svc := srv.Client()
+
+	_, err := svc.CreateBucket(context.Background(), &awss3.CreateBucketInput{
+		Bucket: aws.String("bucket-name"),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	pr, pw := io.Pipe()
+	req, err := http.NewRequest(http.MethodPut, srv.URL+"/bucket-name/key-name", pr)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	// More is written than the connection buffers, so the server is now
+	// reading a body that has not ended.
+	if _, err := pw.Write(bytes.Repeat([]byte("goaws"), 1<<20)); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = svc.ListBuckets(ctx, &awss3.ListBucketsInput{})
+	if err != nil {
+		t.Errorf("expected other requests to be served, got %v", err)
+	}
+
+	pw.Close()
+	<-done
+}
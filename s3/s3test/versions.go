@@ -0,0 +1,291 @@
+package s3test
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const nullVersion = "null"
+
+// versioning returns the bucket's versioning status: "", "Enabled" or
+// "Suspended".
+func (b *bucket) versioning() string {
+	var c versioningConfiguration
+	if err := xml.Unmarshal(b.configs["versioning"], &c); err != nil {
+		return ""
+	}
+
+	return c.Status
+}
+
+// current returns the latest version of key, or nil when the key does not
+// exist or its latest version is a delete marker.
+func (b *bucket) current(key string) *object {
+	versions := b.objects[key]
+	if len(versions) == 0 {
+		return nil
+	}
+
+	o := versions[len(versions)-1]
+	if o.deleteMarker {
+		return nil
+	}
+
+	return o
+}
+
+// lookup finds the version of key a request addresses through its versionId
+// query parameter.
+func (b *bucket) lookup(w http.ResponseWriter, key, versionID string) (*object, *apiError) {
+	if versionID == "" {
+		o := b.current(key)
+		if o == nil {
+			if versions := b.objects[key]; len(versions) > 0 {
+				w.Header().Set("X-Amz-Delete-Marker", "true")
+			}
+
+			return nil, errNoSuchKey
+		}
+
+		return o, nil
+	}
+
+	for _, o := range b.objects[key] {
+		if o.versionID != versionID {
+			continue
+		}
+
+		if o.deleteMarker {
+			w.Header().Set("X-Amz-Delete-Marker", "true")
+			return nil, errMethodNotAllowed
+		}
+
+		return o, nil
+	}
+
+	return nil, errNoSuchVersion
+}
+
+func (b *bucket) newVersionID() string {
+	if b.versioning() == "Enabled" {
+		return uuid.New().String()
+	}
+
+	return nullVersion
+}
+
+// put stores o as the latest version of key. Without versioning enabled it
+// replaces the "null" version.
+func (b *bucket) put(key string, o *object) {
+	o.versionID = b.newVersionID()
+
+	versions := b.objects[key]
+	if o.versionID == nullVersion {
+		versions = removeVersion(versions, nullVersion)
+	}
+
+	b.objects[key] = append(versions, o)
+}
+
+// remove deletes a specific version of key, or when versionID is empty
+// deletes the key the way S3 does: outright in an unversioned bucket and by
+// adding a delete marker otherwise. It returns the removed version or the
+// new marker.
+func (b *bucket) remove(key, versionID string) *object {
+	versions := b.objects[key]
+
+	if versionID == "" {
+		if b.versioning() == "" {
+			b.setVersions(key, removeVersion(versions, nullVersion))
+			return nil
+		}
+
+		marker := &object{
+			deleteMarker: true,
+			modified:     time.Now().UTC(),
+		}
+		b.put(key, marker)
+
+		return marker
+	}
+
+	var removed *object
+	for _, o := range versions {
+		if o.versionID == versionID {
+			removed = o
+		}
+	}
+
+	b.setVersions(key, removeVersion(versions, versionID))
+
+	return removed
+}
+
+func (b *bucket) setVersions(key string, versions []*object) {
+	if len(versions) == 0 {
+		delete(b.objects, key)
+		return
+	}
+
+	b.objects[key] = versions
+}
+
+// currentKeys returns the sorted keys whose latest version is an object.
+func (b *bucket) currentKeys() []string {
+	keys := make([]string, 0, len(b.objects))
+	for k := range b.objects {
+		if b.current(k) != nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func removeVersion(versions []*object, versionID string) []*object {
+	out := versions[:0:0]
+	for _, o := range versions {
+		if o.versionID != versionID {
+			out = append(out, o)
+		}
+	}
+
+	return out
+}
+
+func writeVersionHeader(w http.ResponseWriter, b *bucket, o *object) {
+	if o == nil || (b.versioning() == "" && o.versionID == nullVersion) {
+		return
+	}
+
+	w.Header().Set("X-Amz-Version-Id", o.versionID)
+	if o.deleteMarker {
+		w.Header().Set("X-Amz-Delete-Marker", "true")
+	}
+}
+
+func listObjectVersions(w http.ResponseWriter, name string, b *bucket, q url.Values) *apiError {
+	prefix := q.Get("prefix")
+	delimiter := q.Get("delimiter")
+	keyMarker := q.Get("key-marker")
+	versionMarker := q.Get("version-id-marker")
+
+	maxKeys := 1000
+	if v := q.Get("max-keys"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return errInvalidArgument("invalid max-keys")
+		}
+		if n < maxKeys {
+			maxKeys = n
+		}
+	}
+
+	out := listVersionsResult{
+		Name:            name,
+		Prefix:          prefix,
+		Delimiter:       delimiter,
+		KeyMarker:       keyMarker,
+		VersionIDMarker: versionMarker,
+		MaxKeys:         maxKeys,
+	}
+
+	keys := make([]string, 0, len(b.objects))
+	for k := range b.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	count := 0
+	seen := map[string]bool{}
+
+	var lastKey, lastVersion string
+
+keys:
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) || key < keyMarker {
+			continue
+		}
+		if delimiter != "" && strings.HasSuffix(keyMarker, delimiter) && strings.HasPrefix(key, keyMarker) {
+			continue
+		}
+
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				entry := key[:len(prefix)+i+len(delimiter)]
+				if seen[entry] || entry == keyMarker {
+					continue
+				}
+				if count == maxKeys {
+					out.IsTruncated = true
+					break keys
+				}
+
+				seen[entry] = true
+				count++
+				out.CommonPrefixes = append(out.CommonPrefixes, commonPrefix{Prefix: entry})
+				lastKey, lastVersion = entry, ""
+				continue
+			}
+		}
+
+		versions := b.objects[key]
+		skip := key == keyMarker
+
+		for i := len(versions) - 1; i >= 0; i-- {
+			o := versions[i]
+
+			if skip {
+				if versionMarker != "" && o.versionID == versionMarker {
+					skip = false
+				}
+				continue
+			}
+
+			if count == maxKeys {
+				out.IsTruncated = true
+				break keys
+			}
+
+			count++
+			lastKey, lastVersion = key, o.versionID
+
+			if o.deleteMarker {
+				out.DeleteMarkers = append(out.DeleteMarkers, markerEntry{
+					Key:          key,
+					VersionID:    o.versionID,
+					IsLatest:     i == len(versions)-1,
+					LastModified: o.modified.Format(time.RFC3339Nano),
+				})
+				continue
+			}
+
+			out.Versions = append(out.Versions, versionEntry{
+				Key:          key,
+				VersionID:    o.versionID,
+				IsLatest:     i == len(versions)-1,
+				LastModified: o.modified.Format(time.RFC3339Nano),
+				ETag:         o.etag,
+				Size:         int64(len(o.data)),
+				StorageClass: storageClass(o.header),
+			})
+		}
+	}
+
+	if out.IsTruncated {
+		out.NextKeyMarker = lastKey
+		out.NextVersionIDMarker = lastVersion
+	}
+
+	writeXML(w, http.StatusOK, out)
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package s3
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// SessionOption customizes the client created by NewSession and
+// Bucket.NewSession.
+type SessionOption func(*sessionOptions)
+
+type sessionOptions struct {
+	endpoint    string
+	pathStyle   bool
+	profile     string
+	credentials aws.CredentialsProvider
+	roleARN     string
+	httpClient  aws.HTTPClient
+	retryMode   aws.RetryMode
+	maxAttempts *int
+}
+
+// WithEndpoint sends requests to an S3-compatible service such as MinIO or
+// LocalStack instead of AWS. Most of them also need WithPathStyle.
+func WithEndpoint(endpoint string) SessionOption {
+	return func(o *sessionOptions) {
+		o.endpoint = endpoint
+	}
+}
+
+// WithPathStyle addresses buckets as https://endpoint/bucket/key instead of
+// https://bucket.endpoint/key.
+func WithPathStyle() SessionOption {
+	return func(o *sessionOptions) {
+		o.pathStyle = true
+	}
+}
+
+// WithProfile loads settings and credentials from a named profile in the
+// shared config files.
+func WithProfile(profile string) SessionOption {
+	return func(o *sessionOptions) {
+		o.profile = profile
+	}
+}
+
+func WithStaticCredentials(accessKeyID, secretAccessKey, sessionToken string) SessionOption {
+	return func(o *sessionOptions) {
+		o.credentials = credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, sessionToken)
+	}
+}
+
+// WithAssumeRole makes the session assume roleARN, using the otherwise
+// configured credentials to call STS. Credentials are refreshed before they
+// expire.
+func WithAssumeRole(roleARN string) SessionOption {
+	return func(o *sessionOptions) {
+		o.roleARN = roleARN
+	}
+}
+
+// WithHTTPClient sets the client used to send requests, for example to
+// configure proxies, timeouts or TLS.
+func WithHTTPClient(client aws.HTTPClient) SessionOption {
+	return func(o *sessionOptions) {
+		o.httpClient = client
+	}
+}
+
+// WithRetryMode selects the standard or adaptive retry strategy.
+func WithRetryMode(mode aws.RetryMode) SessionOption {
+	return func(o *sessionOptions) {
+		o.retryMode = mode
+	}
+}
+
+// WithMaxAttempts sets how many times a request is attempted, including
+// the first attempt.
+func WithMaxAttempts(attempts int) SessionOption {
+	return func(o *sessionOptions) {
+		o.maxAttempts = &attempts
+	}
+}
+
+func (o *sessionOptions) validate() error {
+	if o.endpoint != "" {
+		u, err := url.Parse(o.endpoint)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return paramError("Endpoint", "invalid endpoint '%s'", o.endpoint)
+		}
+	}
+	if o.maxAttempts != nil && *o.maxAttempts < 1 {
+		return paramError("MaxAttempts", "'MaxAttempts' must be at least 1")
+	}
+	switch o.retryMode {
+	case "", aws.RetryModeStandard, aws.RetryModeAdaptive:
+	default:
+		return paramError("RetryMode", "unsupported retry mode '%s'", o.retryMode)
+	}
+
+	return nil
+}
+
+// loadConfig loads the SDK config for region with the options applied.
+func (o *sessionOptions) loadConfig(ctx context.Context, region string) (aws.Config, error) {
+	loadOpts := []func(*config.LoadOptions) error{
+		config.WithRegion(region),
+	}
+
+	if o.profile != "" {
+		loadOpts = append(loadOpts, config.WithSharedConfigProfile(o.profile))
+	}
+	if o.credentials != nil {
+		loadOpts = append(loadOpts, config.WithCredentialsProvider(o.credentials))
+	}
+	if o.retryMode != "" {
+		loadOpts = append(loadOpts, config.WithRetryMode(o.retryMode))
+	}
+	if o.maxAttempts != nil {
+		loadOpts = append(loadOpts, config.WithRetryMaxAttempts(*o.maxAttempts))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	// Set after loading: the loader tries to add CA bundles to the client,
+	// which only works for the SDK's own client type.
+	if o.httpClient != nil {
+		cfg.HTTPClient = o.httpClient
+	}
+
+	if o.roleARN != "" {
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), o.roleARN)
+		cfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	return cfg, nil
+}
+
+func (o *sessionOptions) apply(opts *s3.Options) {
+	if o.endpoint != "" {
+		opts.BaseEndpoint = aws.String(o.endpoint)
+	}
+	if o.pathStyle {
+		opts.UsePathStyle = true
+	}
+}
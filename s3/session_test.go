@@ -0,0 +1,163 @@
+package s3_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/itispx/goaws/s3"
+)
+
+func TestNewSession_CustomEndpoint(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	region := "us-east-1"
+	key := "key-name"
+	file := []byte("goaws")
+	httpClient := &recordingClient{}
+
+	svc, err := s3.NewSession(&s3.NewSessionInput{
+		Region: &region,
+	},
+		s3.WithEndpoint("http://localhost:9000"),
+		s3.WithPathStyle(),
+		s3.WithStaticCredentials("AKID", "SECRET", ""),
+		s3.WithHTTPClient(httpClient),
+	)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	bct := s3.Bucket{
+		Name:   &name,
+		Region: &region,
+		Client: svc,
+	}
+
+	_, url, err := bct.UploadObject(&s3.BucketUploadObjectInput{
+		Key:  &key,
+		File: &file,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := "http://localhost:9000/bucket-name/key-name"
+
+	if httpClient.url != expected {
+		t.Errorf("expected request to '%s', got '%s'", expected, httpClient.url)
+	}
+	if url != expected {
+		t.Errorf("expected '%s', got '%s'", expected, url)
+	}
+}
+
+func TestNewSession_Retries(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+
+	svc, err := s3.NewSession(&s3.NewSessionInput{
+		Region: &region,
+	},
+		s3.WithStaticCredentials("AKID", "SECRET", ""),
+		s3.WithRetryMode(aws.RetryModeAdaptive),
+		s3.WithMaxAttempts(5),
+	)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	o := svc.Options()
+
+	if o.RetryMode != aws.RetryModeAdaptive {
+		t.Errorf("expected '%s', got '%s'", aws.RetryModeAdaptive, o.RetryMode)
+	}
+	if o.RetryMaxAttempts != 5 {
+		t.Errorf("expected 5, got %d", o.RetryMaxAttempts)
+	}
+}
+
+func TestNewSession_AssumeRole(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	httpClient := &recordingClient{
+		status: http.StatusForbidden,
+		body:   "<ErrorResponse><Error><Code>AccessDenied</Code></Error></ErrorResponse>",
+	}
+
+	svc, err := s3.NewSession(&s3.NewSessionInput{
+		Region: &region,
+	},
+		s3.WithStaticCredentials("AKID", "SECRET", ""),
+		s3.WithAssumeRole("arn:aws:iam::123456789012:role/goaws"),
+		s3.WithHTTPClient(httpClient),
+	)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = svc.Options().Credentials.Retrieve(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Fatalf("expected STS to deny the request, got %v", err)
+	}
+
+	if !strings.Contains(httpClient.url, "sts.us-east-1.amazonaws.com") {
+		t.Errorf("expected a call to STS, got '%s'", httpClient.url)
+	}
+}
+
+func TestNewSession_InvalidEndpoint(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+
+	_, err := s3.NewSession(&s3.NewSessionInput{
+		Region: &region,
+	}, s3.WithEndpoint("localhost:9000"))
+	if err == nil || err.Error() != "invalid endpoint 'localhost:9000'" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestNewSession_InvalidMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+
+	_, err := s3.NewSession(&s3.NewSessionInput{
+		Region: &region,
+	}, s3.WithMaxAttempts(0))
+	if err == nil || err.Error() != "'MaxAttempts' must be at least 1" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_NewSessionOptions(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	region := "us-east-1"
+
+	bct := s3.Bucket{
+		Name:   &name,
+		Region: &region,
+	}
+
+	svc, err := bct.NewSession(s3.WithEndpoint("http://localhost:4566"), s3.WithPathStyle())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if bct.Client != svc {
+		t.Error("expected the bucket to keep the client")
+	}
+	if aws.ToString(svc.Options().BaseEndpoint) != "http://localhost:4566" || !svc.Options().UsePathStyle {
+		t.Error("expected the endpoint options to be applied")
+	}
+}
@@ -0,0 +1,630 @@
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type BucketSyncInput struct {
+	// Dir is the local directory and Prefix the key prefix it maps to. A
+	// slash is added to Prefix when it does not end with one.
+	Dir    *string
+	Prefix *string
+
+	// Delete removes destination files or objects that are missing from the
+	// source. Only entries matching Include and Exclude are considered.
+	Delete *bool
+
+	// Checksum compares the MD5 of files whose size matches against the
+	// object's ETag instead of comparing modification times. ETags of
+	// multipart, SSE-KMS and SSE-C objects are not MD5s, so those fall back
+	// to times. A mismatching ETag costs a HEAD request to tell them apart.
+	Checksum *bool
+
+	// Include and Exclude are path.Match patterns. A pattern with a slash
+	// is matched against the whole slash-separated path relative to Dir and
+	// one without against the file name, so "*.log" matches "logs/a.log"
+	// while "logs/*.log" does not match "logs/old/a.log". "*" never crosses
+	// a slash and "**" is not supported. With Include set only matching
+	// paths are synced; Exclude then removes paths from that set.
+	Include *[]string
+	Exclude *[]string
+
+	Concurrency *int
+
+	// DryRun builds the report without transferring or deleting anything.
+	DryRun *bool
+}
+
+// SyncEntry is a file in a SyncReport. Reason says why it was copied or
+// skipped.
+type SyncEntry struct {
+	Key    string
+	Path   string
+	Size   int64
+	Reason string
+}
+
+// SyncReport lists what a sync copied, skipped and deleted, sorted by key.
+type SyncReport struct {
+	Copied  []SyncEntry
+	Skipped []SyncEntry
+	Deleted []SyncEntry
+}
+
+const (
+	syncMissing   = "missing"
+	syncSize      = "size differs"
+	syncNewer     = "source is newer"
+	syncChecksum  = "checksum differs"
+	syncUnchanged = "unchanged"
+	syncExtra     = "not in source"
+	syncNotLocal  = "not a local path"
+)
+
+type syncFile struct {
+	rel     string
+	size    int64
+	modTime time.Time
+	etag    string
+}
+
+// SyncFromDir uploads the files in Dir that are missing from the bucket or
+// differ from their object, like `aws s3 sync dir s3://bucket/prefix`. On
+// error the report covers the work done so far.
+func (b *Bucket) SyncFromDir(input *BucketSyncInput) (*SyncReport, error) {
+	return b.SyncFromDirContext(context.Background(), input)
+}
+
+func (b *Bucket) SyncFromDirContext(ctx context.Context, input *BucketSyncInput) (*SyncReport, error) {
+	s, err := b.newSync(ctx, input, false)
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := s.walkDir()
+	if err != nil {
+		return nil, err
+	}
+	remote, err := s.listObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.run(ctx, local, remote, func(ctx context.Context, f syncFile) error {
+		return s.upload(ctx, f)
+	}, func(ctx context.Context, f syncFile) error {
+		_, err := b.DeleteObjectContext(ctx, &BucketDeleteObjectInput{
+			Key: aws.String(s.key(f.rel)),
+		})
+		return err
+	})
+}
+
+// SyncToDir downloads the objects under Prefix that are missing from Dir or
+// differ from the local file, like `aws s3 sync s3://bucket/prefix dir`.
+// Downloaded files get the object's modification time. Keys that would land
+// outside Dir, such as "prefix/../x", are skipped and reported in the error.
+// On error the report covers the work done so far.
+func (b *Bucket) SyncToDir(input *BucketSyncInput) (*SyncReport, error) {
+	return b.SyncToDirContext(context.Background(), input)
+}
+
+func (b *Bucket) SyncToDirContext(ctx context.Context, input *BucketSyncInput) (*SyncReport, error) {
+	s, err := b.newSync(ctx, input, true)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := s.listObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	local, err := s.walkDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.run(ctx, remote, local, func(ctx context.Context, f syncFile) error {
+		return s.download(ctx, f)
+	}, func(ctx context.Context, f syncFile) error {
+		return os.Remove(s.path(f.rel))
+	})
+}
+
+type syncer struct {
+	bucket      *Bucket
+	enc         *Encryption
+	dir         string
+	prefix      string
+	delete      bool
+	checksum    bool
+	include     []string
+	exclude     []string
+	concurrency int
+	dryRun      bool
+
+	// toDir is set when downloading. notLocal then holds the keys whose
+	// path would escape dir.
+	toDir    bool
+	notLocal []SyncEntry
+}
+
+// newSync validates input. A missing Dir is only accepted when toDir is set,
+// as it is when downloading into it.
+func (b *Bucket) newSync(ctx context.Context, input *BucketSyncInput, toDir bool) (*syncer, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input == nil {
+		return nil, nilInput()
+	}
+	if *input == (BucketSyncInput{}) {
+		return nil, emptyInput()
+	}
+	if input.Dir == nil || *input.Dir == "" {
+		return nil, emptyParam("Dir")
+	}
+
+	s := &syncer{
+		bucket:      b,
+		dir:         *input.Dir,
+		prefix:      aws.ToString(input.Prefix),
+		delete:      aws.ToBool(input.Delete),
+		checksum:    aws.ToBool(input.Checksum),
+		concurrency: DefaultConcurrency,
+		dryRun:      aws.ToBool(input.DryRun),
+		toDir:       toDir,
+	}
+
+	enc, err := b.encryption(nil)
+	if err != nil {
+		return nil, err
+	}
+	s.enc = enc
+
+	if s.prefix != "" && !strings.HasSuffix(s.prefix, "/") {
+		s.prefix += "/"
+	}
+
+	if input.Concurrency != nil {
+		if *input.Concurrency < 1 {
+			return nil, paramError("Concurrency", "'Concurrency' must be at least 1")
+		}
+		s.concurrency = *input.Concurrency
+	}
+
+	for _, p := range []struct {
+		field    string
+		patterns *[]string
+		dst      *[]string
+	}{
+		{"Include", input.Include, &s.include},
+		{"Exclude", input.Exclude, &s.exclude},
+	} {
+		if p.patterns == nil {
+			continue
+		}
+		for _, pattern := range *p.patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, paramError(p.field, "invalid pattern '%s'", pattern)
+			}
+		}
+		*p.dst = *p.patterns
+	}
+
+	info, err := os.Stat(s.dir)
+	if err != nil && !(os.IsNotExist(err) && toDir) {
+		return nil, fmt.Errorf("failed to stat dir: %w", err)
+	}
+	if err == nil && !info.IsDir() {
+		return nil, paramError("Dir", "'Dir' is not a directory")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (s *syncer) key(rel string) string {
+	return s.prefix + rel
+}
+
+func (s *syncer) path(rel string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(rel))
+}
+
+func (s *syncer) matches(rel string) bool {
+	if len(s.include) > 0 && !matchPattern(s.include, rel) {
+		return false
+	}
+
+	return !matchPattern(s.exclude, rel)
+}
+
+func matchPattern(patterns []string, rel string) bool {
+	for _, p := range patterns {
+		name := rel
+		if !strings.Contains(p, "/") {
+			name = path.Base(rel)
+		}
+
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *syncer) walkDir() (map[string]syncFile, error) {
+	files := map[string]syncFile{}
+
+	err := filepath.WalkDir(s.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == s.dir {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if !s.matches(rel) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		files[rel] = syncFile{
+			rel:     rel,
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk dir: %w", err)
+	}
+
+	return files, nil
+}
+
+func (s *syncer) listObjects(ctx context.Context) (map[string]syncFile, error) {
+	objects := map[string]syncFile{}
+
+	it := s.bucket.IterateObjectsContext(ctx, &IterateObjectsInput{
+		Prefix: aws.String(s.prefix),
+	})
+	for it.Next() {
+		obj := it.Object()
+
+		rel := strings.TrimPrefix(aws.ToString(obj.Key), s.prefix)
+		if rel == "" || strings.HasSuffix(rel, "/") || !s.matches(rel) {
+			continue
+		}
+
+		// S3 allows ".." segments and absolute-looking keys.
+		if s.toDir && !filepath.IsLocal(filepath.FromSlash(rel)) {
+			s.notLocal = append(s.notLocal, SyncEntry{
+				Key:    aws.ToString(obj.Key),
+				Size:   aws.ToInt64(obj.Size),
+				Reason: syncNotLocal,
+			})
+			continue
+		}
+
+		objects[rel] = syncFile{
+			rel:     rel,
+			size:    aws.ToInt64(obj.Size),
+			modTime: aws.ToTime(obj.LastModified),
+			etag:    strings.Trim(aws.ToString(obj.ETag), `"`),
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+// compare returns why src should be copied over dst, or "" when it can be
+// skipped.
+func (s *syncer) compare(ctx context.Context, src, dst syncFile, exists bool) (string, error) {
+	switch {
+	case !exists:
+		return syncMissing, nil
+	case src.size != dst.size:
+		return syncSize, nil
+	}
+
+	etag, file := src.etag, dst
+	if etag == "" {
+		etag, file = dst.etag, src
+	}
+
+	if s.checksum && etag != "" && !strings.Contains(etag, "-") && !s.enc.opaqueETag() {
+		sum, err := fileMD5(s.path(file.rel))
+		if err != nil {
+			return "", err
+		}
+		if sum == etag {
+			return "", nil
+		}
+
+		// The bucket's default encryption can make an object SSE-KMS
+		// without the setting on Bucket.
+		opaque, err := s.headOpaqueETag(ctx, file.rel)
+		if err != nil {
+			return "", err
+		}
+		if !opaque {
+			return syncChecksum, nil
+		}
+	}
+
+	if src.modTime.After(dst.modTime) {
+		return syncNewer, nil
+	}
+
+	return "", nil
+}
+
+// headOpaqueETag reads the object's encryption to tell whether its ETag is an
+// MD5.
+func (s *syncer) headOpaqueETag(ctx context.Context, rel string) (bool, error) {
+	params := &s3.HeadObjectInput{
+		Bucket: s.bucket.Name,
+		Key:    aws.String(s.key(rel)),
+	}
+	s.enc.applyHeadObject(params)
+
+	head, err := s.bucket.Client.HeadObject(ctx, params)
+	if err != nil {
+		return false, fmt.Errorf("failed to head object: %w", apiError(err))
+	}
+
+	kms := head.ServerSideEncryption == types.ServerSideEncryptionAwsKms || head.ServerSideEncryption == types.ServerSideEncryptionAwsKmsDsse
+
+	return kms || head.SSECustomerAlgorithm != nil, nil
+}
+
+func fileMD5(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// run copies every src entry that differs from dst and, with Delete, removes
+// the dst entries missing from src. Up to concurrency operations run at once.
+func (s *syncer) run(ctx context.Context, src, dst map[string]syncFile, copyFn, deleteFn func(context.Context, syncFile) error) (*SyncReport, error) {
+	w, ctx := newWorkers(ctx)
+
+	type task struct {
+		file   syncFile
+		reason string
+		delete bool
+	}
+
+	var (
+		mu     sync.Mutex
+		report = &SyncReport{Skipped: append([]SyncEntry(nil), s.notLocal...)}
+	)
+
+	tasks := make(chan task)
+
+	w.start(s.concurrency, func() {
+		for t := range tasks {
+			var err error
+			switch {
+			case s.dryRun:
+			case t.delete:
+				err = deleteFn(ctx, t.file)
+			default:
+				err = copyFn(ctx, t.file)
+			}
+			if err != nil {
+				w.fail(err)
+				continue
+			}
+
+			entry := s.entry(t.file, t.reason)
+
+			mu.Lock()
+			if t.delete {
+				report.Deleted = append(report.Deleted, entry)
+			} else {
+				report.Copied = append(report.Copied, entry)
+			}
+			mu.Unlock()
+		}
+	})
+
+	send := func(t task) bool {
+		select {
+		case tasks <- t:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for _, rel := range sortedKeys(src) {
+		d, exists := dst[rel]
+
+		reason, err := s.compare(ctx, src[rel], d, exists)
+		if err != nil {
+			w.fail(err)
+			break
+		}
+
+		if reason == "" {
+			mu.Lock()
+			report.Skipped = append(report.Skipped, s.entry(src[rel], syncUnchanged))
+			mu.Unlock()
+			continue
+		}
+
+		if !send(task{file: src[rel], reason: reason}) {
+			break
+		}
+	}
+
+	if s.delete && ctx.Err() == nil {
+		for _, rel := range sortedKeys(dst) {
+			if _, ok := src[rel]; ok {
+				continue
+			}
+			if !send(task{file: dst[rel], reason: syncExtra, delete: true}) {
+				break
+			}
+		}
+	}
+
+	close(tasks)
+
+	err := w.wait()
+	if err == nil && len(s.notLocal) > 0 {
+		keys := make([]string, 0, len(s.notLocal))
+		for _, e := range s.notLocal {
+			keys = append(keys, e.Key)
+		}
+		err = fmt.Errorf("skipped keys outside 'Dir': %s", strings.Join(keys, ", "))
+	}
+
+	for _, entries := range [][]SyncEntry{report.Copied, report.Skipped, report.Deleted} {
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Key < entries[j].Key
+		})
+	}
+
+	return report, err
+}
+
+func (s *syncer) entry(f syncFile, reason string) SyncEntry {
+	return SyncEntry{
+		Key:    s.key(f.rel),
+		Path:   s.path(f.rel),
+		Size:   f.size,
+		Reason: reason,
+	}
+}
+
+func sortedKeys(files map[string]syncFile) []string {
+	keys := make([]string, 0, len(files))
+	for k := range files {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (s *syncer) upload(ctx context.Context, f syncFile) error {
+	file, err := os.Open(s.path(f.rel))
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	key := aws.String(s.key(f.rel))
+
+	// Small files go up in a single request so their ETag stays an MD5.
+	if f.size <= MinPartSize {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+
+		_, _, err = s.bucket.UploadObjectContext(ctx, &BucketUploadObjectInput{
+			Key:  key,
+			File: &data,
+		})
+		return err
+	}
+
+	_, _, err = s.bucket.UploadMultipartContext(ctx, &BucketUploadMultipartInput{
+		Key:         key,
+		Body:        file,
+		Concurrency: aws.Int(1),
+	})
+	return err
+}
+
+// download writes the object to a temporary file next to its destination
+// and renames it into place, so an interrupted sync leaves no partial files.
+func (s *syncer) download(ctx context.Context, f syncFile) error {
+	dst := s.path(f.rel)
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("failed to create dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = s.bucket.DownloadContext(ctx, &BucketDownloadInput{
+		Key:         aws.String(s.key(f.rel)),
+		Writer:      tmp,
+		Concurrency: aws.Int(1),
+		Verify:      aws.Bool(true),
+	})
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close file: %w", closeErr)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Chtimes(tmp.Name(), f.modTime, f.modTime); err != nil {
+		return fmt.Errorf("failed to set modification time: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return fmt.Errorf("failed to rename file: %w", err)
+	}
+
+	return nil
+}
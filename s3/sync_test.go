@@ -0,0 +1,319 @@
+package s3_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/itispx/goaws/s3"
+	"github.com/itispx/goaws/s3/s3test"
+)
+
+func writeSyncDir(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+
+	for name, data := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+
+		err := os.MkdirAll(filepath.Dir(p), 0o755)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		err = os.WriteFile(p, []byte(data), 0o644)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	return dir
+}
+
+func syncKeys(entries []s3.SyncEntry) []string {
+	keys := []string{}
+	for _, e := range entries {
+		keys = append(keys, e.Key)
+	}
+
+	return keys
+}
+
+func TestBucket_Sync(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	src := writeSyncDir(t, map[string]string{
+		"a.txt":        "goaws",
+		"dir/b.txt":    "goaws",
+		"skip.log":     "goaws",
+		"dir/skip.log": "goaws",
+	})
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	input := &s3.BucketSyncInput{
+		Dir:      &src,
+		Prefix:   aws.String("sync"),
+		Exclude:  &[]string{"*.log"},
+		Checksum: aws.Bool(true),
+	}
+
+	report, err := bct.SyncFromDir(input)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(report.Copied) != 2 {
+		t.Errorf("expected 2 uploads, got %v", syncKeys(report.Copied))
+	}
+
+	report, err = bct.SyncFromDir(input)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(report.Copied) != 0 || len(report.Skipped) != 2 {
+		t.Errorf("expected everything to be skipped, got %+v", report)
+	}
+
+	dst := t.TempDir()
+
+	report, err = bct.SyncToDir(&s3.BucketSyncInput{
+		Dir:    &dst,
+		Prefix: aws.String("sync"),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(report.Copied) != 2 {
+		t.Errorf("expected 2 downloads, got %v", syncKeys(report.Copied))
+	}
+
+	data, err := os.ReadFile(filepath.Join(dst, "dir", "b.txt"))
+	if err != nil || string(data) != "goaws" {
+		t.Errorf("unexpected downloaded file '%s', %v", data, err)
+	}
+
+	t.Cleanup(func() {
+		for _, k := range []string{"sync/a.txt", "sync/dir/b.txt"} {
+			err := deleteObject(bucket, region, k)
+			if err != nil {
+				t.Errorf("cleanup fail: %s", err.Error())
+			}
+		}
+
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func TestBucket_SyncToDirKeyOutsideDir(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	keys := []string{"p/a.txt", "p/../../escaped.txt"}
+	for _, k := range keys {
+		err = putObject(bucket, region, k)
+		if err != nil {
+			t.Errorf("setup fail: %s", err.Error())
+		}
+	}
+
+	root := t.TempDir()
+	dst := filepath.Join(root, "a", "b")
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	report, err := bct.SyncToDir(&s3.BucketSyncInput{
+		Dir:    &dst,
+		Prefix: aws.String("p"),
+	})
+	if err == nil || err.Error() != "skipped keys outside 'Dir': p/../../escaped.txt" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if copied := syncKeys(report.Copied); len(copied) != 1 || copied[0] != "p/a.txt" {
+		t.Errorf("unexpected downloads %v", copied)
+	}
+	if len(report.Skipped) != 1 || report.Skipped[0].Reason != "not a local path" {
+		t.Errorf("unexpected skipped %+v", report.Skipped)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "escaped.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected no file outside 'Dir', got %v", err)
+	}
+
+	t.Cleanup(func() {
+		for _, k := range keys {
+			err := deleteObject(bucket, region, k)
+			if err != nil {
+				t.Errorf("cleanup fail: %s", err.Error())
+			}
+		}
+
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func TestBucket_SyncChecksumEncrypted(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSyncDir(t, map[string]string{
+		"a.txt": "goaws",
+	})
+
+	tests := []struct {
+		name     string
+		sse      types.ServerSideEncryption
+		expected string
+	}{
+		{"KMS", types.ServerSideEncryptionAwsKms, "unchanged"},
+		{"S3", types.ServerSideEncryptionAes256, "checksum differs"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mock := &s3test.Mock{}
+			mock.On("ListObjectsV2", &awss3.ListObjectsV2Output{
+				Contents: []types.Object{{
+					Key:          aws.String("p/a.txt"),
+					Size:         aws.Int64(5),
+					ETag:         aws.String(`"0123456789abcdef"`),
+					LastModified: aws.Time(time.Now().Add(time.Hour)),
+				}},
+			}, nil)
+			mock.On("HeadObject", &awss3.HeadObjectOutput{ServerSideEncryption: tt.sse}, nil)
+
+			bct := s3.Bucket{
+				Name:   aws.String("bucket-name"),
+				Client: mock,
+			}
+
+			report, err := bct.SyncFromDir(&s3.BucketSyncInput{
+				Dir:      &dir,
+				Prefix:   aws.String("p"),
+				Checksum: aws.Bool(true),
+				DryRun:   aws.Bool(true),
+			})
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			entries := append(report.Copied, report.Skipped...)
+			if len(entries) != 1 || entries[0].Reason != tt.expected {
+				t.Errorf("expected '%s', got %+v", tt.expected, entries)
+			}
+		})
+	}
+}
+
+func TestBucket_SyncFromDirDryRun(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSyncDir(t, map[string]string{
+		"a.txt":     "goaws",
+		"dir/b.txt": "goaws",
+		"skip.log":  "goaws",
+	})
+
+	client := &listingClient{keys: []string{"p/a.txt", "p/old.txt", "p/keep.log"}, pageSize: 1000}
+	bct := newListingBucket(t, client)
+
+	report, err := bct.SyncFromDir(&s3.BucketSyncInput{
+		Dir:     &dir,
+		Prefix:  aws.String("p"),
+		Exclude: &[]string{"*.log"},
+		Delete:  aws.Bool(true),
+		DryRun:  aws.Bool(true),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	copied := syncKeys(report.Copied)
+	if len(copied) != 2 || copied[0] != "p/a.txt" || copied[1] != "p/dir/b.txt" {
+		t.Errorf("unexpected uploads %v", copied)
+	}
+	if report.Copied[0].Reason != "size differs" || report.Copied[1].Reason != "missing" {
+		t.Errorf("unexpected reasons %+v", report.Copied)
+	}
+
+	deleted := syncKeys(report.Deleted)
+	if len(deleted) != 1 || deleted[0] != "p/old.txt" {
+		t.Errorf("unexpected deletes %v", deleted)
+	}
+
+	if client.requests != 1 {
+		t.Errorf("expected only the listing request, got %d", client.requests)
+	}
+}
+
+func TestBucket_SyncFromDirInvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	dir := t.TempDir()
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.SyncFromDir(&s3.BucketSyncInput{
+		Dir:     &dir,
+		Include: &[]string{"[a-"},
+	})
+	if err == nil || err.Error() != "invalid pattern '[a-'" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_SyncFromDirEmptyDir(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.SyncFromDir(&s3.BucketSyncInput{
+		Prefix: aws.String("p"),
+	})
+	if err == nil || err.Error() != "empty 'Dir' param" {
+		t.Error("invalid error message")
+	}
+}
@@ -0,0 +1,203 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// EnableVersioning turns on versioning for the bucket. It can be suspended
+// but never turned off again.
+func (b *Bucket) EnableVersioning() (*s3.PutBucketVersioningOutput, error) {
+	return b.EnableVersioningContext(context.Background())
+}
+
+func (b *Bucket) EnableVersioningContext(ctx context.Context) (*s3.PutBucketVersioningOutput, error) {
+	return b.putVersioning(ctx, types.BucketVersioningStatusEnabled)
+}
+
+// SuspendVersioning stops new versions from being created. Existing versions
+// are kept; a bucket can never return to the unversioned state.
+func (b *Bucket) SuspendVersioning() (*s3.PutBucketVersioningOutput, error) {
+	return b.SuspendVersioningContext(context.Background())
+}
+
+func (b *Bucket) SuspendVersioningContext(ctx context.Context) (*s3.PutBucketVersioningOutput, error) {
+	return b.putVersioning(ctx, types.BucketVersioningStatusSuspended)
+}
+
+func (b *Bucket) putVersioning(ctx context.Context, status types.BucketVersioningStatus) (*s3.PutBucketVersioningOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := b.Client.PutBucketVersioning(ctx, &s3.PutBucketVersioningInput{
+		Bucket: b.Name,
+		VersioningConfiguration: &types.VersioningConfiguration{
+			Status: status,
+		},
+	}, withoutAccelerate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put bucket versioning: %w", apiError(err))
+	}
+
+	return out, nil
+}
+
+// VersioningStatus returns Enabled, Suspended, or an empty status for a
+// bucket that never had versioning turned on.
+func (b *Bucket) VersioningStatus() (types.BucketVersioningStatus, error) {
+	return b.VersioningStatusContext(context.Background())
+}
+
+func (b *Bucket) VersioningStatusContext(ctx context.Context) (types.BucketVersioningStatus, error) {
+	if b.Name == nil || *b.Name == "" {
+		return "", emptyParam("Name")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	out, err := b.Client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{
+		Bucket: b.Name,
+	}, withoutAccelerate)
+	if err != nil {
+		return "", fmt.Errorf("failed to get bucket versioning: %w", apiError(err))
+	}
+
+	return out.Status, nil
+}
+
+type ListObjectVersionsInput struct {
+	// Limit caps the number of versions, delete markers and common prefixes
+	// returned across all pages. Without it every page is fetched.
+	Limit *int
+	*s3.ListObjectVersionsInput
+}
+
+type ListObjectVersionsOutput struct {
+	Versions       []types.ObjectVersion
+	DeleteMarkers  []types.DeleteMarkerEntry
+	CommonPrefixes []types.CommonPrefix
+
+	// IsTruncated is set when Limit stopped the listing early. Pass the next
+	// markers back as KeyMarker and VersionIdMarker to continue.
+	IsTruncated         bool
+	NextKeyMarker       *string
+	NextVersionIdMarker *string
+}
+
+// ListObjectVersions lists the versions and delete markers in the bucket,
+// holding every page in memory unless Limit is set. Use IterateObjectVersions
+// or ObjectVersions to walk them a page at a time.
+func (b *Bucket) ListObjectVersions(input *ListObjectVersionsInput) (*ListObjectVersionsOutput, error) {
+	return b.ListObjectVersionsContext(context.Background(), input)
+}
+
+func (b *Bucket) ListObjectVersionsContext(ctx context.Context, input *ListObjectVersionsInput) (*ListObjectVersionsOutput, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, emptyParam("Name")
+	}
+	if input != nil && input.Limit != nil && *input.Limit < 1 {
+		return nil, paramError("Limit", "'Limit' must be at least 1")
+	}
+
+	if b.Client == nil {
+		_, err := b.NewSessionContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if input == nil {
+		input = &ListObjectVersionsInput{}
+	}
+
+	var params s3.ListObjectVersionsInput
+	if input.ListObjectVersionsInput != nil {
+		params = *input.ListObjectVersionsInput
+	}
+	params.Bucket = b.Name
+
+	out := &ListObjectVersionsOutput{}
+	count := 0
+
+	for {
+		if input.Limit != nil {
+			params.MaxKeys = aws.Int32(int32(min(*input.Limit-count, 1000)))
+		}
+
+		page, err := b.Client.ListObjectVersions(ctx, &params, withoutAccelerate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list object versions: %w", apiError(err))
+		}
+
+		out.Versions = append(out.Versions, page.Versions...)
+		out.DeleteMarkers = append(out.DeleteMarkers, page.DeleteMarkers...)
+		out.CommonPrefixes = append(out.CommonPrefixes, page.CommonPrefixes...)
+		count += len(page.Versions) + len(page.DeleteMarkers) + len(page.CommonPrefixes)
+
+		if !aws.ToBool(page.IsTruncated) {
+			return out, nil
+		}
+
+		if input.Limit != nil && count >= *input.Limit {
+			out.IsTruncated = true
+			out.NextKeyMarker = page.NextKeyMarker
+			out.NextVersionIdMarker = page.NextVersionIdMarker
+
+			return out, nil
+		}
+
+		params.KeyMarker = page.NextKeyMarker
+		params.VersionIdMarker = page.NextVersionIdMarker
+	}
+}
+
+type BucketRestoreObjectVersionInput struct {
+	Key       *string
+	VersionId *string
+}
+
+// RestoreObjectVersion makes an earlier version current again by copying it
+// over the latest one. The copy becomes a new version; history is kept.
+func (b *Bucket) RestoreObjectVersion(input *BucketRestoreObjectVersionInput) (*CopyObjectOutput, string, error) {
+	return b.RestoreObjectVersionContext(context.Background(), input)
+}
+
+func (b *Bucket) RestoreObjectVersionContext(ctx context.Context, input *BucketRestoreObjectVersionInput) (*CopyObjectOutput, string, error) {
+	if b.Name == nil || *b.Name == "" {
+		return nil, "", emptyParam("Name")
+	}
+	if input == nil {
+		return nil, "", nilInput()
+	}
+	if *input == (BucketRestoreObjectVersionInput{}) {
+		return nil, "", emptyInput()
+	}
+	if input.Key == nil || *input.Key == "" {
+		return nil, "", emptyParam("Key")
+	}
+	if input.VersionId == nil || *input.VersionId == "" {
+		return nil, "", emptyParam("VersionId")
+	}
+
+	return b.CopyObjectContext(ctx, &BucketCopyObjectInput{
+		Key:             input.Key,
+		SourceVersionId: input.VersionId,
+	})
+}
@@ -0,0 +1,232 @@
+package s3_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/itispx/goaws/s3"
+)
+
+func TestBucket_Versioning(t *testing.T) {
+	t.Parallel()
+
+	region := "us-east-1"
+	bucket, err := createBucket(region)
+	if err != nil {
+		t.Errorf("setup fail: %s", err.Error())
+	}
+
+	// Finish setup
+
+	bct := s3.Bucket{
+		Name:   &bucket,
+		Region: &region,
+	}
+
+	_, err = bct.EnableVersioning()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	status, err := bct.VersioningStatus()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if status != types.BucketVersioningStatusEnabled {
+		t.Errorf("expected status '%s', got '%s'", types.BucketVersioningStatusEnabled, status)
+	}
+
+	key := "versioned"
+
+	var versions []string
+	for _, content := range []string{"first", "second"} {
+		file := []byte(content)
+
+		out, _, err := bct.UploadObject(&s3.BucketUploadObjectInput{
+			Key:  &key,
+			File: &file,
+		})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		versions = append(versions, *out.VersionId)
+	}
+
+	got, err := bct.GetObject(&s3.BucketGetObjectInput{
+		Key:       &key,
+		VersionId: &versions[0],
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	body, err := io.ReadAll(got.Body)
+	got.Body.Close()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if string(body) != "first" {
+		t.Errorf("expected 'first', got '%s'", body)
+	}
+
+	_, _, err = bct.RestoreObjectVersion(&s3.BucketRestoreObjectVersionInput{
+		Key:       &key,
+		VersionId: &versions[0],
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	got, err = bct.GetObject(&s3.BucketGetObjectInput{
+		Key: &key,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	body, err = io.ReadAll(got.Body)
+	got.Body.Close()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if string(body) != "first" {
+		t.Errorf("expected restored 'first', got '%s'", body)
+	}
+
+	deleted, err := bct.DeleteObject(&s3.BucketDeleteObjectInput{
+		Key: &key,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if deleted.DeleteMarker == nil || !*deleted.DeleteMarker {
+		t.Error("expected a delete marker")
+	}
+
+	limit := 2
+
+	list, err := bct.ListObjectVersions(&s3.ListObjectVersionsInput{
+		Limit: &limit,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(list.Versions)+len(list.DeleteMarkers) != limit || !list.IsTruncated {
+		t.Errorf("expected %d truncated entries, got %d", limit, len(list.Versions)+len(list.DeleteMarkers))
+	}
+
+	list, err = bct.ListObjectVersions(nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(list.Versions) != 3 || len(list.DeleteMarkers) != 1 {
+		t.Errorf("expected 3 versions and 1 delete marker, got %d and %d", len(list.Versions), len(list.DeleteMarkers))
+	}
+
+	_, err = bct.SuspendVersioning()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	t.Cleanup(func() {
+		for _, v := range list.Versions {
+			_, err = bct.DeleteObject(&s3.BucketDeleteObjectInput{
+				Key:       v.Key,
+				VersionId: v.VersionId,
+			})
+			if err != nil {
+				t.Errorf("cleanup fail: %s", err.Error())
+			}
+		}
+
+		for _, m := range list.DeleteMarkers {
+			_, err = bct.DeleteObject(&s3.BucketDeleteObjectInput{
+				Key:       m.Key,
+				VersionId: m.VersionId,
+			})
+			if err != nil {
+				t.Errorf("cleanup fail: %s", err.Error())
+			}
+		}
+
+		err = deleteBucket(bucket, region)
+		if err != nil {
+			t.Errorf("cleanup fail: %s", err.Error())
+		}
+	})
+}
+
+func TestBucket_ListObjectVersionsZeroLimit(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	limit := 0
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, err := bct.ListObjectVersions(&s3.ListObjectVersionsInput{
+		Limit: &limit,
+	})
+	if err == nil || err.Error() != "'Limit' must be at least 1" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_RestoreObjectVersionNilInput(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.RestoreObjectVersion(nil)
+	if err == nil || err.Error() != "nil input" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_RestoreObjectVersionEmptyInput(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.RestoreObjectVersion(&s3.BucketRestoreObjectVersionInput{})
+	if err == nil || err.Error() != "empty input" {
+		t.Error("invalid error message")
+	}
+}
+
+func TestBucket_RestoreObjectVersionEmptyVersionId(t *testing.T) {
+	t.Parallel()
+
+	name := "bucket-name"
+	key := "key-name"
+
+	bct := s3.Bucket{
+		Name: &name,
+	}
+
+	_, _, err := bct.RestoreObjectVersion(&s3.BucketRestoreObjectVersionInput{
+		Key: &key,
+	})
+	if err == nil || err.Error() != "empty 'VersionId' param" {
+		t.Error("invalid error message")
+	}
+}
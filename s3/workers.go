@@ -0,0 +1,65 @@
+package s3
+
+import (
+	"context"
+	"sync"
+)
+
+// workers is a pool of goroutines sharing a context that the first failure
+// cancels, so the others stop early.
+type workers struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
+}
+
+// newWorkers returns the pool and the context its goroutines should use.
+func newWorkers(ctx context.Context) (*workers, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &workers{ctx: ctx, cancel: cancel}, ctx
+}
+
+// start runs fn in n goroutines.
+func (w *workers) start(n int, fn func()) {
+	for i := 0; i < n; i++ {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			fn()
+		}()
+	}
+}
+
+// fail records err unless an earlier error was recorded, and cancels the
+// pool's context.
+func (w *workers) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err == nil {
+		w.err = err
+		w.cancel()
+	}
+}
+
+// wait waits for the goroutines, releases the context and returns the first
+// error.
+func (w *workers) wait() error {
+	w.wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// The caller's context can end between tasks without any of them
+	// failing, which must not pass for success.
+	if w.err == nil {
+		w.err = w.ctx.Err()
+	}
+	w.cancel()
+
+	return w.err
+}